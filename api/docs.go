@@ -38,12 +38,70 @@ const docTemplate = `{
                 }
             }
         },
-        "/v1/html2pdf": {
+        "/v1/html2image": {
             "post": {
-                "description": "Retrieve the pdf file of a html",
+                "description": "Retrieve a PNG, JPEG or WebP screenshot of a html, loaded and awaited as for a pdf",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
+                "tags": [
+                    "HTML IMAGE"
+                ],
+                "summary": "API Convert html to image",
+                "parameters": [
+                    {
+                        "description": "The input ImageRequest struct",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.ImageRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "waitFor condition not met, or data not matching its schema",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "no browser available",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/html2pdf": {
+            "post": {
+                "description": "Retrieve the pdf file of a html. Besides JSON, the request can be a multipart form\nwith an index.html file, its assets named by relative path and the JSON options in\nthe request field, or a ZIP bundle holding index.html, its assets and request.json.\nThe pdf is returned as a raw application/pdf attachment instead of the JSON envelope\nwhen the Accept header prefers application/pdf or the binary query flag is set.\nWith a CallbackURL the request is answered at once with a job, whose outcome is\nposted to the URL as a signed dtos.WebhookEvent.",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data",
+                    "application/zip"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/pdf"
+                ],
                 "tags": [
                     "HTML PDF"
                 ],
@@ -57,6 +115,189 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dtos.HtmlRequest"
                         }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the raw pdf",
+                        "name": "binary",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "202": {
+                        "description": "job queued, outcome posted to the CallbackURL",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "pdf too large",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "waitFor condition not met, or data not matching its schema",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "no browser available",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/html2pdf/batch": {
+            "post": {
+                "description": "Render the items concurrently and stream a ZIP archive of their pdfs, each named after\nits Filename, ending with a manifest.json listing the dtos.BatchItemResult of every\nitem. A failed item is reported in the manifest without failing the batch.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/zip",
+                    "application/json"
+                ],
+                "tags": [
+                    "HTML PDF"
+                ],
+                "summary": "API Convert a batch of html to pdf",
+                "parameters": [
+                    {
+                        "description": "The input BatchRequest struct",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BatchRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "ZIP archive of the pdfs and manifest.json",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/html2pdf/mailmerge": {
+            "post": {
+                "description": "Render the document once per row of the file uploaded in the data field, the first row\nnaming the columns. The {{column}} placeholders of the content are filled with the values\nof the row, HTML escaped, and a template gets them as its data. The form carries the\ndocument and its assets as by /v1/html2pdf, and the dtos.MailMergeRequest options in the\nrequest field. With the pdf output the documents are merged, with a bookmark per row, the\nfailed rows being left out and listed in the X-Failed-Rows header of a raw pdf. With the\nzip output the archive holds the pdf of each row, named by the FilenamePattern, and a\nmanifest.json reporting the outcome of every row.",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/pdf",
+                    "application/zip"
+                ],
+                "tags": [
+                    "HTML PDF"
+                ],
+                "summary": "API Mail merge html with the rows of a CSV or XLSX file",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "The MailMergeRequest options, as JSON",
+                        "name": "request",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "The CSV or XLSX file",
+                        "name": "data",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the raw pdf",
+                        "name": "binary",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "413": {
+                        "description": "pdf too large",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "every row failed",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/html2pdf/merge": {
+            "post": {
+                "description": "Render each section with its own print options, header and footer, and concatenate\nthem into a single pdf with a bookmark per section. The page numbers of the headers\nand footers run through the whole document. The pdf is returned as a raw\napplication/pdf attachment as by /v1/html2pdf on request.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "application/pdf"
+                ],
+                "tags": [
+                    "HTML PDF"
+                ],
+                "summary": "API Merge html sections into a pdf",
+                "parameters": [
+                    {
+                        "description": "The input MergeRequest struct",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.MergeRequest"
+                        }
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Return the raw pdf",
+                        "name": "binary",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -71,98 +312,1166 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dtos.BaseResponse"
                         }
+                    },
+                    "413": {
+                        "description": "pdf too large",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "422": {
+                        "description": "waitFor condition not met, or data not matching its schema",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "no browser available",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs": {
+            "post": {
+                "description": "Queue the conversion of a html, accepted as by /v1/html2pdf, and return the job to poll",
+                "consumes": [
+                    "application/json",
+                    "multipart/form-data",
+                    "application/zip"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JOBS"
+                ],
+                "summary": "API Submit a html to pdf job",
+                "parameters": [
+                    {
+                        "description": "The input HtmlRequest struct",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.HtmlRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "job queued",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "503": {
+                        "description": "job queue full",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{id}": {
+            "get": {
+                "description": "Retrieve the status of a job: queued, rendering, done, failed or canceled",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JOBS"
+                ],
+                "summary": "API Get a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Cancel a queued or rendering job, or delete a finished one along with its pdf",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "JOBS"
+                ],
+                "summary": "API Cancel a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/jobs/{id}/result": {
+            "get": {
+                "description": "Retrieve the pdf of a done job as an application/pdf attachment",
+                "produces": [
+                    "application/pdf",
+                    "application/json"
+                ],
+                "tags": [
+                    "JOBS"
+                ],
+                "summary": "API Get the pdf of a job",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Job ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "the pdf",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "404": {
+                        "description": "job not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "job not done",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/profiles": {
+            "get": {
+                "description": "Retrieve the rendering profiles of the server with their settings. A request names one\nin its Profile field to take the print options, header and footer templates and CSS it\nleaves out from the profile.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "PROFILES"
+                ],
+                "summary": "API List the rendering profiles",
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/templates": {
+            "get": {
+                "description": "Retrieve the templates of the registry, with their version numbers",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TEMPLATES"
+                ],
+                "summary": "API List the templates",
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Store a html/template document, layout or partial as the version 1 of a new template.\nA document renders within its layout, which shows it with {{template \"content\" .}},\nand its partials and the ones of its layout are available under their ID. The\nlayout and partials are referenced by ID, optionally followed by @version. Render\nrequests then reference the document in TemplateName as ID or ID@version.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TEMPLATES"
+                ],
+                "summary": "API Create a template",
+                "parameters": [
+                    {
+                        "description": "The input TemplateRequest struct",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "template created",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "template already exists",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/templates/{id}": {
+            "get": {
+                "description": "Retrieve a template with its latest version, or the version given as ID@version",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TEMPLATES"
+                ],
+                "summary": "API Get a template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "template not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Store a new version of a template, the previous ones remaining available. The kind\nof a template cannot change.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TEMPLATES"
+                ],
+                "summary": "API Update a template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "The input TemplateRequest struct",
+                        "name": "Request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dtos.TemplateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "template updated",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "template not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete a template along with all its versions. Layouts and partials other templates\nstill use cannot be deleted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TEMPLATES"
+                ],
+                "summary": "API Delete a template",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "template deleted",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "template not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "409": {
+                        "description": "template in use",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/v1/templates/{id}/versions/{version}": {
+            "get": {
+                "description": "Retrieve a template with one of its versions",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "TEMPLATES"
+                ],
+                "summary": "API Get a template version",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Template ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Template version",
+                        "name": "version",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "success",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "error",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "template not found",
+                        "schema": {
+                            "$ref": "#/definitions/dtos.BaseResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "controllers.GetHealthCheckResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.BaseResponse": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer"
+                },
+                "errors": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Error"
+                    }
+                },
+                "message": {
+                    "type": "string"
+                },
+                "result": {},
+                "success": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.BasicAuth": {
+            "type": "object",
+            "required": [
+                "username"
+            ],
+            "properties": {
+                "password": {
+                    "type": "string"
+                },
+                "username": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.BatchRequest": {
+            "type": "object",
+            "required": [
+                "items"
+            ],
+            "properties": {
+                "items": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dtos.HtmlRequest"
+                    }
+                }
+            }
+        },
+        "dtos.Cookie": {
+            "type": "object",
+            "required": [
+                "name"
+            ],
+            "properties": {
+                "domain": {
+                    "type": "string"
+                },
+                "httponly": {
+                    "type": "boolean"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "path": {
+                    "type": "string"
+                },
+                "secure": {
+                    "type": "boolean"
+                },
+                "value": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.Error": {
+            "type": "object",
+            "properties": {
+                "detail": {
+                    "type": "string"
+                },
+                "title": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.HtmlRequest": {
+            "type": "object",
+            "properties": {
+                "autoHeight": {
+                    "description": "AutoHeight prints the document on a single page as high as its\ncontent laid out at the paper width, PaperHeight being ignored. A\ndocument higher than the maximum height of the server is refused.\nIt excludes Landscape and PreferCSSPageSize.",
+                    "type": "boolean",
+                    "default": false
+                },
+                "basicAuth": {
+                    "$ref": "#/definitions/dtos.BasicAuth"
+                },
+                "callbackURL": {
+                    "description": "CallbackURL makes the render asynchronous: the request is answered\nwith a job and its outcome is posted to the URL.",
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "contentCss": {
+                    "type": "string"
+                },
+                "cookies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Cookie"
+                    }
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "displayHeaderFooter": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "filename": {
+                    "description": "Filename names the pdf when it is returned as a binary attachment.",
+                    "type": "string"
+                },
+                "footerTemplate": {
+                    "type": "string"
+                },
+                "headerTemplate": {
+                    "type": "string"
+                },
+                "headers": {
+                    "description": "Headers and BasicAuth are sent along the requests to the URL origin.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "inserts": {
+                    "description": "Inserts are existing pdfs placed into the rendered one, in order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.PdfInsert"
+                    }
+                },
+                "landscape": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "marginBottom": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "marginLeft": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "marginRight": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "0"
+                },
+                "marginTop": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "offline": {
+                    "description": "Offline blocks every request of the page but the ones to its assets.",
+                    "type": "boolean"
+                },
+                "paperFormat": {
+                    "description": "PaperFormat names the paper size: A3, A4, A5, Letter, Legal,\nThermal58 or Thermal80, the thermal receipt rolls of 58 and 80mm,\nwhich have no page height and print with AutoHeight. It excludes\nPaperWidth and PaperHeight.",
+                    "type": "string",
+                    "example": "A4"
+                },
+                "paperHeight": {
+                    "type": "string",
+                    "default": "11",
+                    "example": "297mm"
+                },
+                "paperWidth": {
+                    "type": "string",
+                    "default": "8.5",
+                    "example": "210mm"
+                },
+                "preferCSSPageSize": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "printBackground": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "profile": {
+                    "description": "Profile names a rendering profile of the server, whose settings\napply to the ones the request leaves out.",
+                    "type": "string"
+                },
+                "schema": {
+                    "description": "Data is validated against the JSON Schema, inline or stored on the\nserver under SchemaName, before anything is rendered. Without a\ntemplate, its values fill the {{field}} placeholders of Content,\nfield being a dotted path such as customer.name or items.0.price.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "schemaName": {
+                    "type": "string"
+                },
+                "scripts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Script"
+                    }
+                },
+                "stylesheets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Stylesheet"
+                    }
+                },
+                "template": {
+                    "description": "Template, inline or from the registry under TemplateName, is\nexecuted with Data as html/template to build the content. The\nTemplateName is a template ID, optionally followed by @version.",
+                    "type": "string"
+                },
+                "templateName": {
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is rendered instead of Content, which must then be empty. Its\nhost must be allowed by the server configuration.",
+                    "type": "string"
+                },
+                "waitElementId": {
+                    "type": "string"
+                },
+                "waitFor": {
+                    "$ref": "#/definitions/dtos.WaitFor"
+                },
+                "withScale": {
+                    "description": "WithScale scales the content, between 0.1 and 2.",
+                    "type": "number",
+                    "default": 1
+                }
+            }
+        },
+        "dtos.ImageRequest": {
+            "type": "object",
+            "properties": {
+                "autoHeight": {
+                    "description": "AutoHeight prints the document on a single page as high as its\ncontent laid out at the paper width, PaperHeight being ignored. A\ndocument higher than the maximum height of the server is refused.\nIt excludes Landscape and PreferCSSPageSize.",
+                    "type": "boolean",
+                    "default": false
+                },
+                "basicAuth": {
+                    "$ref": "#/definitions/dtos.BasicAuth"
+                },
+                "callbackURL": {
+                    "description": "CallbackURL makes the render asynchronous: the request is answered\nwith a job and its outcome is posted to the URL.",
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "contentCss": {
+                    "type": "string"
+                },
+                "cookies": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Cookie"
+                    }
+                },
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "deviceScaleFactor": {
+                    "type": "number",
+                    "default": 1,
+                    "maximum": 4
+                },
+                "displayHeaderFooter": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "filename": {
+                    "description": "Filename names the pdf when it is returned as a binary attachment.",
+                    "type": "string"
+                },
+                "footerTemplate": {
+                    "type": "string"
+                },
+                "format": {
+                    "type": "string",
+                    "default": "png",
+                    "enum": [
+                        "png",
+                        "jpeg",
+                        "webp"
+                    ]
+                },
+                "fullPage": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "headerTemplate": {
+                    "type": "string"
+                },
+                "headers": {
+                    "description": "Headers and BasicAuth are sent along the requests to the URL origin.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "inserts": {
+                    "description": "Inserts are existing pdfs placed into the rendered one, in order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.PdfInsert"
+                    }
+                },
+                "landscape": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "marginBottom": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "marginLeft": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "marginRight": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "0"
+                },
+                "marginTop": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "offline": {
+                    "description": "Offline blocks every request of the page but the ones to its assets.",
+                    "type": "boolean"
+                },
+                "paperFormat": {
+                    "description": "PaperFormat names the paper size: A3, A4, A5, Letter, Legal,\nThermal58 or Thermal80, the thermal receipt rolls of 58 and 80mm,\nwhich have no page height and print with AutoHeight. It excludes\nPaperWidth and PaperHeight.",
+                    "type": "string",
+                    "example": "A4"
+                },
+                "paperHeight": {
+                    "type": "string",
+                    "default": "11",
+                    "example": "297mm"
+                },
+                "paperWidth": {
+                    "type": "string",
+                    "default": "8.5",
+                    "example": "210mm"
+                },
+                "preferCSSPageSize": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "printBackground": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "profile": {
+                    "description": "Profile names a rendering profile of the server, whose settings\napply to the ones the request leaves out.",
+                    "type": "string"
+                },
+                "quality": {
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1
+                },
+                "schema": {
+                    "description": "Data is validated against the JSON Schema, inline or stored on the\nserver under SchemaName, before anything is rendered. Without a\ntemplate, its values fill the {{field}} placeholders of Content,\nfield being a dotted path such as customer.name or items.0.price.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "schemaName": {
+                    "type": "string"
+                },
+                "scripts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Script"
+                    }
+                },
+                "selector": {
+                    "type": "string"
+                },
+                "stylesheets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Stylesheet"
+                    }
+                },
+                "template": {
+                    "description": "Template, inline or from the registry under TemplateName, is\nexecuted with Data as html/template to build the content. The\nTemplateName is a template ID, optionally followed by @version.",
+                    "type": "string"
+                },
+                "templateName": {
+                    "type": "string"
+                },
+                "transparent": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "url": {
+                    "description": "URL is rendered instead of Content, which must then be empty. Its\nhost must be allowed by the server configuration.",
+                    "type": "string"
+                },
+                "viewportHeight": {
+                    "type": "integer",
+                    "default": 800,
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "viewportWidth": {
+                    "type": "integer",
+                    "default": 1280,
+                    "maximum": 10000,
+                    "minimum": 1
+                },
+                "waitElementId": {
+                    "type": "string"
+                },
+                "waitFor": {
+                    "$ref": "#/definitions/dtos.WaitFor"
+                },
+                "withScale": {
+                    "description": "WithScale scales the content, between 0.1 and 2.",
+                    "type": "number",
+                    "default": 1
                 }
             }
-        }
-    },
-    "definitions": {
-        "controllers.GetHealthCheckResponse": {
+        },
+        "dtos.MergeRequest": {
             "type": "object",
+            "required": [
+                "sections"
+            ],
             "properties": {
-                "message": {
+                "filename": {
+                    "description": "Filename names the pdf when it is returned as a binary attachment.",
                     "type": "string"
+                },
+                "sections": {
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dtos.MergeSection"
+                    }
                 }
             }
         },
-        "dtos.BaseResponse": {
+        "dtos.MergeSection": {
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer"
+                "autoHeight": {
+                    "description": "AutoHeight prints the document on a single page as high as its\ncontent laid out at the paper width, PaperHeight being ignored. A\ndocument higher than the maximum height of the server is refused.\nIt excludes Landscape and PreferCSSPageSize.",
+                    "type": "boolean",
+                    "default": false
                 },
-                "errors": {
+                "basicAuth": {
+                    "$ref": "#/definitions/dtos.BasicAuth"
+                },
+                "callbackURL": {
+                    "description": "CallbackURL makes the render asynchronous: the request is answered\nwith a job and its outcome is posted to the URL.",
+                    "type": "string"
+                },
+                "content": {
+                    "type": "string"
+                },
+                "contentCss": {
+                    "type": "string"
+                },
+                "cookies": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dtos.Error"
+                        "$ref": "#/definitions/dtos.Cookie"
                     }
                 },
-                "message": {
+                "data": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "displayHeaderFooter": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "filename": {
+                    "description": "Filename names the pdf when it is returned as a binary attachment.",
                     "type": "string"
                 },
-                "result": {},
-                "success": {
+                "footerTemplate": {
+                    "type": "string"
+                },
+                "headerTemplate": {
+                    "type": "string"
+                },
+                "headers": {
+                    "description": "Headers and BasicAuth are sent along the requests to the URL origin.",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "inserts": {
+                    "description": "Inserts are existing pdfs placed into the rendered one, in order.",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.PdfInsert"
+                    }
+                },
+                "landscape": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "marginBottom": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "marginLeft": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "marginRight": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "0"
+                },
+                "marginTop": {
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "offline": {
+                    "description": "Offline blocks every request of the page but the ones to its assets.",
                     "type": "boolean"
+                },
+                "paperFormat": {
+                    "description": "PaperFormat names the paper size: A3, A4, A5, Letter, Legal,\nThermal58 or Thermal80, the thermal receipt rolls of 58 and 80mm,\nwhich have no page height and print with AutoHeight. It excludes\nPaperWidth and PaperHeight.",
+                    "type": "string",
+                    "example": "A4"
+                },
+                "paperHeight": {
+                    "type": "string",
+                    "default": "11",
+                    "example": "297mm"
+                },
+                "paperWidth": {
+                    "type": "string",
+                    "default": "8.5",
+                    "example": "210mm"
+                },
+                "preferCSSPageSize": {
+                    "type": "boolean",
+                    "default": false
+                },
+                "printBackground": {
+                    "type": "boolean",
+                    "default": true
+                },
+                "profile": {
+                    "description": "Profile names a rendering profile of the server, whose settings\napply to the ones the request leaves out.",
+                    "type": "string"
+                },
+                "schema": {
+                    "description": "Data is validated against the JSON Schema, inline or stored on the\nserver under SchemaName, before anything is rendered. Without a\ntemplate, its values fill the {{field}} placeholders of Content,\nfield being a dotted path such as customer.name or items.0.price.",
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "schemaName": {
+                    "type": "string"
+                },
+                "scripts": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Script"
+                    }
+                },
+                "stylesheets": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dtos.Stylesheet"
+                    }
+                },
+                "template": {
+                    "description": "Template, inline or from the registry under TemplateName, is\nexecuted with Data as html/template to build the content. The\nTemplateName is a template ID, optionally followed by @version.",
+                    "type": "string"
+                },
+                "templateName": {
+                    "type": "string"
+                },
+                "title": {
+                    "description": "Title is the bookmark of the section, \"Section \u003cn\u003e\" by default.",
+                    "type": "string"
+                },
+                "url": {
+                    "description": "URL is rendered instead of Content, which must then be empty. Its\nhost must be allowed by the server configuration.",
+                    "type": "string"
+                },
+                "waitElementId": {
+                    "type": "string"
+                },
+                "waitFor": {
+                    "$ref": "#/definitions/dtos.WaitFor"
+                },
+                "withScale": {
+                    "description": "WithScale scales the content, between 0.1 and 2.",
+                    "type": "number",
+                    "default": 1
                 }
             }
         },
-        "dtos.Error": {
+        "dtos.PdfInsert": {
             "type": "object",
             "properties": {
-                "detail": {
+                "file": {
+                    "description": "File refers to an uploaded pdf by its path among the assets.",
                     "type": "string"
                 },
-                "title": {
+                "name": {
+                    "description": "Name refers to \u003cname\u003e.pdf in the inserts directory of the server.",
                     "type": "string"
+                },
+                "page": {
+                    "type": "integer",
+                    "minimum": 1
+                },
+                "position": {
+                    "type": "string",
+                    "default": "after",
+                    "enum": [
+                        "before",
+                        "after"
+                    ]
                 }
             }
         },
-        "dtos.HtmlRequest": {
+        "dtos.PrintOptions": {
             "type": "object",
-            "required": [
-                "content"
-            ],
             "properties": {
-                "content": {
-                    "type": "string"
+                "autoHeight": {
+                    "description": "AutoHeight prints the document on a single page as high as its\ncontent laid out at the paper width, PaperHeight being ignored. A\ndocument higher than the maximum height of the server is refused.\nIt excludes Landscape and PreferCSSPageSize.",
+                    "type": "boolean",
+                    "default": false
                 },
                 "displayHeaderFooter": {
                     "type": "boolean",
-                    "default": true
-                },
-                "footerTemplate": {
-                    "type": "string"
-                },
-                "headerTemplate": {
-                    "type": "string"
+                    "default": false
                 },
                 "landscape": {
                     "type": "boolean",
                     "default": false
                 },
                 "marginBottom": {
-                    "type": "number",
-                    "default": 1
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
                 },
                 "marginLeft": {
-                    "type": "number",
-                    "default": 1
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
                 },
                 "marginRight": {
-                    "type": "number",
-                    "default": 0
+                    "type": "string",
+                    "default": "0",
+                    "example": "0"
                 },
                 "marginTop": {
-                    "type": "number",
-                    "default": 1
+                    "type": "string",
+                    "default": "0",
+                    "example": "10mm"
+                },
+                "paperFormat": {
+                    "description": "PaperFormat names the paper size: A3, A4, A5, Letter, Legal,\nThermal58 or Thermal80, the thermal receipt rolls of 58 and 80mm,\nwhich have no page height and print with AutoHeight. It excludes\nPaperWidth and PaperHeight.",
+                    "type": "string",
+                    "example": "A4"
                 },
                 "paperHeight": {
-                    "type": "number",
-                    "default": 11.69
+                    "type": "string",
+                    "default": "11",
+                    "example": "297mm"
                 },
                 "paperWidth": {
-                    "type": "number",
-                    "default": 8.27
+                    "type": "string",
+                    "default": "8.5",
+                    "example": "210mm"
                 },
                 "preferCSSPageSize": {
                     "type": "boolean",
@@ -170,11 +1479,177 @@ const docTemplate = `{
                 },
                 "printBackground": {
                     "type": "boolean",
-                    "default": false
+                    "default": true
                 },
                 "withScale": {
+                    "description": "WithScale scales the content, between 0.1 and 2.",
                     "type": "number",
-                    "default": 0.57
+                    "default": 1
+                }
+            }
+        },
+        "dtos.Script": {
+            "type": "object",
+            "required": [
+                "stage"
+            ],
+            "properties": {
+                "name": {
+                    "type": "string"
+                },
+                "source": {
+                    "type": "string"
+                },
+                "stage": {
+                    "type": "string",
+                    "enum": [
+                        "beforeLoad",
+                        "afterLoad",
+                        "beforePrint"
+                    ]
+                }
+            }
+        },
+        "dtos.Stylesheet": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "dtos.TemplateRequest": {
+            "type": "object",
+            "required": [
+                "content"
+            ],
+            "properties": {
+                "content": {
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "css": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "footerTemplate": {
+                    "type": "string"
+                },
+                "headerTemplate": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "kind": {
+                    "type": "string",
+                    "default": "document",
+                    "enum": [
+                        "document",
+                        "layout",
+                        "partial"
+                    ]
+                },
+                "layout": {
+                    "type": "string"
+                },
+                "options": {
+                    "$ref": "#/definitions/dtos.PrintOptions"
+                },
+                "partials": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "version": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.WaitExpression": {
+            "type": "object",
+            "required": [
+                "expression"
+            ],
+            "properties": {
+                "expression": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.WaitFor": {
+            "type": "object",
+            "properties": {
+                "delay": {
+                    "type": "integer"
+                },
+                "expression": {
+                    "$ref": "#/definitions/dtos.WaitExpression"
+                },
+                "fonts": {
+                    "$ref": "#/definitions/dtos.WaitSignal"
+                },
+                "networkIdle": {
+                    "$ref": "#/definitions/dtos.WaitNetworkIdle"
+                },
+                "readySignal": {
+                    "$ref": "#/definitions/dtos.WaitSignal"
+                },
+                "selector": {
+                    "$ref": "#/definitions/dtos.WaitSelector"
+                }
+            }
+        },
+        "dtos.WaitNetworkIdle": {
+            "type": "object",
+            "required": [
+                "idleTime"
+            ],
+            "properties": {
+                "idleTime": {
+                    "type": "integer"
+                },
+                "timeout": {
+                    "type": "integer"
+                }
+            }
+        },
+        "dtos.WaitSelector": {
+            "type": "object",
+            "required": [
+                "selector"
+            ],
+            "properties": {
+                "selector": {
+                    "type": "string"
+                },
+                "timeout": {
+                    "type": "integer"
+                },
+                "visible": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "dtos.WaitSignal": {
+            "type": "object",
+            "properties": {
+                "timeout": {
+                    "type": "integer"
                 }
             }
         }
@@ -1,15 +1,30 @@
 package configs
 
 import (
+	"runtime"
+	"strings"
+	"time"
+
 	"github.com/spf13/viper"
 )
 
 var cfg *config
 
 type config struct {
-	Log     Log
-	Server  Server
-	Swagger Swagger
+	Log       Log
+	Server    Server
+	Swagger   Swagger
+	Chrome    Chrome
+	Scripts   Scripts
+	Templates Templates
+	Schemas   Schemas
+	Profiles  Profiles
+	Network   Network
+	URLSource URLSource
+	Pdf       Pdf
+	Jobs      Jobs
+	Webhook   Webhook
+	Batch     Batch
 }
 
 type Log struct {
@@ -18,18 +33,146 @@ type Log struct {
 }
 
 type Server struct {
-	Port string
+	Port          string
+	MaxUploadSize int64
 }
 
 type Swagger struct {
 	Enabled bool
 }
 
+type Chrome struct {
+	ExecPath            string
+	RemoteURLs          []string
+	Balancer            string
+	PoolSize            int
+	TabsPerBrowser      int
+	ReconnectAttempts   int
+	ReconnectInterval   time.Duration
+	HealthCheckInterval time.Duration
+	RecycleAfterRenders int
+	RecycleAfter        time.Duration
+	RenderRetries       int
+	TabTimeout          time.Duration
+	RenderTimeout       time.Duration
+}
+
+type Scripts struct {
+	Dir string
+}
+
+// Templates configures the template registry. The filesystem backend keeps
+// the templates in Dir.
+type Templates struct {
+	Dir     string
+	Backend string
+}
+
+type Schemas struct {
+	Dir string
+}
+
+// Profiles configures the rendering profiles, one JSON file each in Dir.
+type Profiles struct {
+	Dir string
+}
+
+type Jobs struct {
+	Workers   int
+	QueueSize int
+	Timeout   time.Duration
+	TTL       time.Duration
+}
+
+// Webhook configures the callbacks of the jobs. Their events are signed
+// with Secret, and callbacks are refused while it is empty.
+type Webhook struct {
+	Secret        string
+	MaxAttempts   int
+	Backoff       time.Duration
+	Timeout       time.Duration
+	InlineMaxSize int
+	PublicURL     string
+}
+
+type Batch struct {
+	Concurrency int
+	MaxItems    int
+}
+
+type Pdf struct {
+	Filename   string
+	MaxSize    int64
+	InsertsDir string
+	// MaxAutoHeight bounds the height of the auto height pages, in inches;
+	// higher documents are refused.
+	MaxAutoHeight float64
+}
+
+type URLSource struct {
+	AllowHosts []string
+}
+
+// Network configures the requests a rendered page may send. Loopback,
+// private and link-local addresses are denied by default, so pages cannot
+// reach the DevTools port, the API itself or the cloud metadata; operators
+// opt in to them by setting NETWORK_DENY_CIDRS without those ranges.
+type Network struct {
+	AllowHosts   []string
+	DenyHosts    []string
+	AllowSchemes []string
+	AllowCIDRs   []string
+	DenyCIDRs    []string
+	Offline      bool
+}
+
 func init() {
 	viper.SetDefault("PORT", "8080")
+	viper.SetDefault("SERVER_MAX_UPLOAD_SIZE", 32<<20)
 	viper.SetDefault("LOG_ENVIRONMENT", "")
 	viper.SetDefault("LOG_APPLICATION", "")
 	viper.SetDefault("SWAGGER_ENABLED", false)
+	viper.SetDefault("CHROME_EXEC_PATH", "")
+	viper.SetDefault("CHROME_POOL_SIZE", runtime.NumCPU())
+	viper.SetDefault("CHROME_TABS_PER_BROWSER", 4)
+	viper.SetDefault("CHROME_REMOTE_URL", "")
+	viper.SetDefault("CHROME_RECONNECT_ATTEMPTS", 3)
+	viper.SetDefault("CHROME_RECONNECT_INTERVAL", "1s")
+	viper.SetDefault("CHROME_BALANCER", "least-busy")
+	viper.SetDefault("CHROME_HEALTH_CHECK_INTERVAL", "10s")
+	viper.SetDefault("CHROME_RECYCLE_AFTER_RENDERS", 200)
+	viper.SetDefault("CHROME_RECYCLE_AFTER", "30m")
+	viper.SetDefault("CHROME_RENDER_RETRIES", 1)
+	viper.SetDefault("CHROME_TAB_TIMEOUT", "10s")
+	viper.SetDefault("CHROME_RENDER_TIMEOUT", "20s")
+	viper.SetDefault("SCRIPTS_DIR", "scripts")
+	viper.SetDefault("TEMPLATES_DIR", "templates")
+	viper.SetDefault("TEMPLATES_BACKEND", "filesystem")
+	viper.SetDefault("SCHEMAS_DIR", "schemas")
+	viper.SetDefault("PROFILES_DIR", "profiles")
+	viper.SetDefault("NETWORK_ALLOW_HOSTS", "")
+	viper.SetDefault("NETWORK_DENY_HOSTS", "metadata,metadata.google.internal")
+	viper.SetDefault("NETWORK_ALLOW_SCHEMES", "http,https,data,blob")
+	viper.SetDefault("NETWORK_ALLOW_CIDRS", "")
+	viper.SetDefault("NETWORK_DENY_CIDRS", "0.0.0.0/8,127.0.0.0/8,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,100.64.0.0/10,169.254.0.0/16,::/128,::1/128,fc00::/7,fe80::/10,100.100.100.200/32,fd00:ec2::254/128")
+	viper.SetDefault("NETWORK_OFFLINE", false)
+	viper.SetDefault("URL_ALLOW_HOSTS", "")
+	viper.SetDefault("PDF_FILENAME", "document.pdf")
+	viper.SetDefault("PDF_MAX_SIZE", 256<<20)
+	viper.SetDefault("PDF_INSERTS_DIR", "inserts")
+	viper.SetDefault("PDF_MAX_AUTO_HEIGHT", 200)
+	viper.SetDefault("JOBS_WORKERS", runtime.NumCPU())
+	viper.SetDefault("JOBS_QUEUE_SIZE", 100)
+	viper.SetDefault("JOBS_TIMEOUT", "10m")
+	viper.SetDefault("JOBS_TTL", "1h")
+	viper.SetDefault("WEBHOOK_SECRET", "")
+	viper.SetDefault("WEBHOOK_MAX_ATTEMPTS", 5)
+	viper.SetDefault("WEBHOOK_BACKOFF", "1s")
+	viper.SetDefault("WEBHOOK_TIMEOUT", "10s")
+	viper.SetDefault("WEBHOOK_INLINE_MAX_SIZE", 5<<20)
+	viper.SetDefault("WEBHOOK_PUBLIC_URL", "")
+	viper.SetDefault("BATCH_CONCURRENCY", 4)
+	viper.SetDefault("BATCH_MAX_ITEMS", 100)
 
 	viper.AddConfigPath(".")
 	viper.SetConfigFile(".env")
@@ -51,12 +194,87 @@ func init() {
 			Application: la,
 		},
 		Server: Server{
-			Port: viper.GetString("PORT"),
+			Port:          viper.GetString("PORT"),
+			MaxUploadSize: viper.GetInt64("SERVER_MAX_UPLOAD_SIZE"),
 		},
 		Swagger: Swagger{
 			Enabled: viper.GetBool("SWAGGER_ENABLED"),
 		},
+		Chrome: Chrome{
+			ExecPath:            viper.GetString("CHROME_EXEC_PATH"),
+			RemoteURLs:          splitList(viper.GetString("CHROME_REMOTE_URL")),
+			Balancer:            viper.GetString("CHROME_BALANCER"),
+			PoolSize:            viper.GetInt("CHROME_POOL_SIZE"),
+			TabsPerBrowser:      viper.GetInt("CHROME_TABS_PER_BROWSER"),
+			ReconnectAttempts:   viper.GetInt("CHROME_RECONNECT_ATTEMPTS"),
+			ReconnectInterval:   viper.GetDuration("CHROME_RECONNECT_INTERVAL"),
+			HealthCheckInterval: viper.GetDuration("CHROME_HEALTH_CHECK_INTERVAL"),
+			RecycleAfterRenders: viper.GetInt("CHROME_RECYCLE_AFTER_RENDERS"),
+			RecycleAfter:        viper.GetDuration("CHROME_RECYCLE_AFTER"),
+			RenderRetries:       viper.GetInt("CHROME_RENDER_RETRIES"),
+			TabTimeout:          viper.GetDuration("CHROME_TAB_TIMEOUT"),
+			RenderTimeout:       viper.GetDuration("CHROME_RENDER_TIMEOUT"),
+		},
+		Scripts: Scripts{
+			Dir: viper.GetString("SCRIPTS_DIR"),
+		},
+		Templates: Templates{
+			Dir:     viper.GetString("TEMPLATES_DIR"),
+			Backend: viper.GetString("TEMPLATES_BACKEND"),
+		},
+		Schemas: Schemas{
+			Dir: viper.GetString("SCHEMAS_DIR"),
+		},
+		Profiles: Profiles{
+			Dir: viper.GetString("PROFILES_DIR"),
+		},
+		Network: Network{
+			AllowHosts:   splitList(viper.GetString("NETWORK_ALLOW_HOSTS")),
+			DenyHosts:    splitList(viper.GetString("NETWORK_DENY_HOSTS")),
+			AllowSchemes: splitList(viper.GetString("NETWORK_ALLOW_SCHEMES")),
+			AllowCIDRs:   splitList(viper.GetString("NETWORK_ALLOW_CIDRS")),
+			DenyCIDRs:    splitList(viper.GetString("NETWORK_DENY_CIDRS")),
+			Offline:      viper.GetBool("NETWORK_OFFLINE"),
+		},
+		URLSource: URLSource{
+			AllowHosts: splitList(viper.GetString("URL_ALLOW_HOSTS")),
+		},
+		Pdf: Pdf{
+			Filename:      viper.GetString("PDF_FILENAME"),
+			MaxSize:       viper.GetInt64("PDF_MAX_SIZE"),
+			InsertsDir:    viper.GetString("PDF_INSERTS_DIR"),
+			MaxAutoHeight: viper.GetFloat64("PDF_MAX_AUTO_HEIGHT"),
+		},
+		Jobs: Jobs{
+			Workers:   viper.GetInt("JOBS_WORKERS"),
+			QueueSize: viper.GetInt("JOBS_QUEUE_SIZE"),
+			Timeout:   viper.GetDuration("JOBS_TIMEOUT"),
+			TTL:       viper.GetDuration("JOBS_TTL"),
+		},
+		Webhook: Webhook{
+			Secret:        viper.GetString("WEBHOOK_SECRET"),
+			MaxAttempts:   viper.GetInt("WEBHOOK_MAX_ATTEMPTS"),
+			Backoff:       viper.GetDuration("WEBHOOK_BACKOFF"),
+			Timeout:       viper.GetDuration("WEBHOOK_TIMEOUT"),
+			InlineMaxSize: viper.GetInt("WEBHOOK_INLINE_MAX_SIZE"),
+			PublicURL:     viper.GetString("WEBHOOK_PUBLIC_URL"),
+		},
+		Batch: Batch{
+			Concurrency: viper.GetInt("BATCH_CONCURRENCY"),
+			MaxItems:    viper.GetInt("BATCH_MAX_ITEMS"),
+		},
+	}
+}
+
+// splitList splits a comma separated setting, dropping blank items.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
 }
 
 func GetConfig() config {
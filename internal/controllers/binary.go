@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const mimePDF = "application/pdf"
+
+// wantsBinary tells whether the client asked for the raw document, with an
+// Accept header preferring contentType or the binary query flag, instead of
+// the JSON envelope.
+func wantsBinary(c *gin.Context, contentType string) bool {
+	if binary, err := strconv.ParseBool(c.Query("binary")); err == nil {
+		return binary
+	}
+	return c.NegotiateFormat(gin.MIMEJSON, contentType) == contentType
+}
+
+// attachmentWriter writes the response headers of an attachment with the
+// first chunk of its content.
+type attachmentWriter struct {
+	c           *gin.Context
+	contentType string
+	filename    string
+	started     bool
+}
+
+func newAttachmentWriter(c *gin.Context, contentType string, filename string) *attachmentWriter {
+	return &attachmentWriter{c: c, contentType: contentType, filename: filename}
+}
+
+func (w *attachmentWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.started = true
+		w.c.Header("Content-Type", w.contentType)
+		w.c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": w.filename}))
+		w.c.Status(200)
+	}
+	return w.c.Writer.Write(p)
+}
+
+// abortStream resets a response whose body was started, so that the client
+// sees it was cut short. The panic is let through by RecoveryMiddleware and
+// makes the server close the HTTP/1.1 connection or reset the HTTP/2 stream.
+func abortStream(c *gin.Context) {
+	panic(http.ErrAbortHandler)
+}
+
+// attachmentName keeps the base name of filename and makes sure it has an
+// extension matching contentType.
+func attachmentName(filename string, fallback string, contentType string) string {
+	filename = path.Base(strings.ReplaceAll(strings.TrimSpace(filename), "\\", "/"))
+	if filename == "." || filename == "/" {
+		filename = fallback
+	}
+	if extensions, _ := mime.ExtensionsByType(contentType); len(extensions) > 0 {
+		ext := path.Ext(filename)
+		for _, extension := range extensions {
+			if strings.EqualFold(ext, extension) {
+				return filename
+			}
+		}
+		filename += extensions[0]
+	}
+	return filename
+}
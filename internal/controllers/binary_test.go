@@ -0,0 +1,110 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBinaryResponse(t *testing.T) {
+	t.Parallel()
+
+	wantsBinary := func(target string, accept string) bool {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest("POST", target, nil)
+		if accept != "" {
+			c.Request.Header.Set("Accept", accept)
+		}
+		return controllers.WantsBinary(c, "application/pdf")
+	}
+
+	t.Run("TestWantsBinary", func(t *testing.T) {
+		assert.False(t, wantsBinary("/html2pdf", ""))
+		assert.False(t, wantsBinary("/html2pdf", "*/*"))
+		assert.False(t, wantsBinary("/html2pdf", "application/json"))
+		assert.True(t, wantsBinary("/html2pdf", "application/pdf"))
+		assert.True(t, wantsBinary("/html2pdf", "application/pdf, application/json"))
+		assert.True(t, wantsBinary("/html2pdf?binary=true", "application/json"))
+		assert.False(t, wantsBinary("/html2pdf?binary=0", "application/pdf"))
+	})
+
+	t.Run("TestAttachmentName", func(t *testing.T) {
+		assert.Equal(t, "document.pdf", controllers.AttachmentName("", "document.pdf", "application/pdf"))
+		assert.Equal(t, "invoice.pdf", controllers.AttachmentName("invoice", "document.pdf", "application/pdf"))
+		assert.Equal(t, "invoice.PDF", controllers.AttachmentName("invoice.PDF", "document.pdf", "application/pdf"))
+		assert.Equal(t, "passwd.pdf", controllers.AttachmentName("../../etc/passwd", "document.pdf", "application/pdf"))
+		assert.Equal(t, "report.pdf", controllers.AttachmentName(`C:\tmp\report.pdf`, "document.pdf", "application/pdf"))
+	})
+
+	t.Run("TestAttachmentWriter", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+
+		aw := controllers.NewAttachmentWriter(c, "application/pdf", "relatório.pdf")
+		aw.Write([]byte("%PDF-"))
+		aw.Write([]byte("1.4"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+		assert.Equal(t, "attachment; filename*=utf-8''relat%C3%B3rio.pdf", w.Header().Get("Content-Disposition"))
+		assert.Equal(t, "%PDF-1.4", w.Body.String())
+	})
+
+	t.Run("TestAbortStream", func(t *testing.T) {
+		router := gin.New()
+		router.Use(middlewares.RecoveryMiddleware(logger.NewFakeLogger()))
+		router.GET("/pdf", func(c *gin.Context) {
+			controllers.NewAttachmentWriter(c, "application/pdf", "document.pdf").Write([]byte("%PDF-1.4"))
+			c.Writer.Flush()
+			controllers.AbortStream(c)
+		})
+
+		for _, http2 := range []bool{false, true} {
+			ts := httptest.NewUnstartedServer(router)
+			ts.EnableHTTP2 = http2
+			ts.StartTLS()
+
+			res, err := ts.Client().Get(ts.URL + "/pdf")
+			if assert.NoError(t, err) {
+				assert.Equal(t, http2, res.ProtoMajor == 2)
+				assert.Equal(t, http.StatusOK, res.StatusCode)
+				_, err = io.ReadAll(res.Body)
+				assert.Error(t, err, "http2: %t", http2)
+				res.Body.Close()
+			}
+			ts.Close()
+		}
+	})
+
+	t.Run("HandleHttp2PdfBinary", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+		hc := controllers.NewHtml2PdfController(logger)
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.POST("/html2pdf", hc.HandleHttp2Pdf)
+
+		body, _ := json.Marshal(dtos.HtmlRequest{Content: jsonContent, Filename: "contract"})
+		req, _ := http.NewRequest("POST", "/html2pdf", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "application/pdf")
+		r.ServeHTTP(w, req)
+
+		if w.Code == http.StatusOK {
+			assert.Equal(t, "application/pdf", w.Header().Get("Content-Type"))
+			assert.Equal(t, `attachment; filename=contract.pdf`, w.Header().Get("Content-Disposition"))
+		} else {
+			assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+		}
+	})
+}
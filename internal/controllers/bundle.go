@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+const (
+	// bundleIndex is the document printed out of a multipart form or a ZIP
+	// bundle, the other files are served to it as assets.
+	bundleIndex = "index.html"
+	// bundleOptions holds the JSON HtmlRequest options of a ZIP bundle.
+	bundleOptions = "request.json"
+	// bundleOptionsField and bundleField are the multipart fields holding the
+	// JSON HtmlRequest options and a ZIP bundle.
+	bundleOptionsField = "request"
+	bundleField        = "bundle"
+)
+
+var errBundleTooLarge = errors.New("bundle exceeds the maximum upload size")
+
+// bindHtmlRequest reads the request from a JSON body, a multipart form or a
+// ZIP bundle, depending on the Content-Type.
+func bindHtmlRequest(c *gin.Context, request *dtos.HtmlRequest, maxSize int64) error {
+	switch c.ContentType() {
+	case binding.MIMEMultipartPOSTForm:
+		return bindMultipart(c, request, maxSize)
+	case "application/zip", "application/x-zip-compressed":
+		return bindZip(c, request, maxSize)
+	}
+	return c.ShouldBindJSON(request)
+}
+
+// bindMultipart reads a form holding an index.html file, the assets it
+// refers to under their relative path as field name, an optional ZIP bundle
+// and the JSON HtmlRequest options in the request field.
+func bindMultipart(c *gin.Context, request *dtos.HtmlRequest, maxSize int64) error {
+	return bindForm(c, request, request, maxSize)
+}
+
+// bindForm reads a form as bindMultipart does, the JSON options going to
+// options, the struct embedding request.
+func bindForm(c *gin.Context, options interface{}, request *dtos.HtmlRequest, maxSize int64) error {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize)
+	form, err := c.MultipartForm()
+	if err != nil {
+		return err
+	}
+
+	assets := map[string][]byte{}
+	if values := form.Value[bundleOptionsField]; len(values) > 0 {
+		if err := json.Unmarshal([]byte(values[0]), options); err != nil {
+			return fmt.Errorf("%s field: %w", bundleOptionsField, err)
+		}
+	}
+	for field, files := range form.File {
+		for _, header := range files {
+			file, err := header.Open()
+			if err != nil {
+				return err
+			}
+			data, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				return err
+			}
+			if field == bundleField {
+				if err := readZip(data, assets, maxSize); err != nil {
+					return err
+				}
+				continue
+			}
+			name, err := assetPath(field)
+			if err != nil {
+				return err
+			}
+			assets[name] = data
+		}
+	}
+	return bindAssets(options, request, assets)
+}
+
+// bindZip reads a ZIP bundle holding an index.html file, the assets it
+// refers to and optionally the JSON HtmlRequest options in request.json.
+func bindZip(c *gin.Context, request *dtos.HtmlRequest, maxSize int64) error {
+	data, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSize))
+	if err != nil {
+		return err
+	}
+	assets := map[string][]byte{}
+	if err := readZip(data, assets, maxSize); err != nil {
+		return err
+	}
+	if options, ok := assets[bundleOptions]; ok {
+		delete(assets, bundleOptions)
+		if err := json.Unmarshal(options, request); err != nil {
+			return fmt.Errorf("%s: %w", bundleOptions, err)
+		}
+	}
+	return bindAssets(request, request, assets)
+}
+
+// readZip adds the files of the archive to assets, refusing archives that
+// expand beyond maxSize.
+func readZip(data []byte, assets map[string][]byte, maxSize int64) error {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	remaining := maxSize
+	for _, file := range archive.File {
+		if file.FileInfo().IsDir() {
+			continue
+		}
+		name, err := assetPath(file.Name)
+		if err != nil {
+			return err
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, remaining+1))
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		if remaining -= int64(len(content)); remaining < 0 {
+			return errBundleTooLarge
+		}
+		assets[name] = content
+	}
+	return nil
+}
+
+// bindAssets takes the document out of the assets and validates the
+// options as the JSON binding does.
+func bindAssets(options interface{}, request *dtos.HtmlRequest, assets map[string][]byte) error {
+	if index, ok := assets[bundleIndex]; ok {
+		request.Content = string(index)
+	}
+	request.Assets = assets
+	return binding.Validator.ValidateStruct(options)
+}
+
+// assetPath is the slash separated path an asset is served under, relative
+// to the document. It never leaves the bundle root.
+func assetPath(name string) (string, error) {
+	name = path.Clean("/" + strings.ReplaceAll(name, "\\", "/"))
+	if name == "/" {
+		return "", fmt.Errorf("invalid asset name %q", name)
+	}
+	return name[1:], nil
+}
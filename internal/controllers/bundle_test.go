@@ -0,0 +1,95 @@
+package controllers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHtml2PdfBundle(t *testing.T) {
+	t.Parallel()
+
+	logger := logger.NewFakeLogger()
+	hc := controllers.NewHtml2PdfController(logger)
+
+	serve := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.POST("/html2pdf", hc.HandleHttp2Pdf)
+
+		req, _ := http.NewRequest("POST", "/html2pdf", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("HandleHttp2PdfMultipartWithoutIndex", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("request", `{"Landscape": true}`)
+		part, _ := form.CreateFormFile("img/logo.png", "logo.png")
+		part.Write([]byte("png"))
+		form.Close()
+
+		w := serve(form.FormDataContentType(), body.Bytes())
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHttp2PdfMultipartInvalidOptions", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("request", `{`)
+		part, _ := form.CreateFormFile("index.html", "index.html")
+		part.Write([]byte(jsonContent))
+		form.Close()
+
+		w := serve(form.FormDataContentType(), body.Bytes())
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHttp2PdfMultipart", func(t *testing.T) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		part, _ := form.CreateFormFile("index.html", "index.html")
+		part.Write([]byte(`<img src="img/logo.png">`))
+		part, _ = form.CreateFormFile("img/logo.png", "logo.png")
+		part.Write([]byte("png"))
+		form.Close()
+
+		w := serve(form.FormDataContentType(), body.Bytes())
+
+		assert.NotEqual(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHttp2PdfZip", func(t *testing.T) {
+		var body bytes.Buffer
+		archive := zip.NewWriter(&body)
+		file, _ := archive.Create("index.html")
+		file.Write([]byte(`<link rel="stylesheet" href="css/site.css">`))
+		file, _ = archive.Create("css/site.css")
+		file.Write([]byte("body {}"))
+		file, _ = archive.Create("request.json")
+		file.Write([]byte(`{"Landscape": true}`))
+		archive.Close()
+
+		w := serve("application/zip", body.Bytes())
+
+		assert.NotEqual(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHttp2PdfInvalidZip", func(t *testing.T) {
+		w := serve("application/zip", []byte("not a zip"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
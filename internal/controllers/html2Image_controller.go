@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// @Summary API Convert html to image
+// @Description Retrieve a PNG, JPEG or WebP screenshot of a html, loaded and awaited as for a pdf
+// @Tags HTML IMAGE
+// @Accept json
+// @Produce json
+// @Version 1.0
+// @Param Request body dtos.ImageRequest true "The input ImageRequest struct"
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 422 {object} dtos.BaseResponse "waitFor condition not met, or data not matching its schema"
+// @Failure 503 {object} dtos.BaseResponse "no browser available"
+// @Router /v1/html2image [post]
+func (h *Http2PdfController) HandleHtml2Image(c *gin.Context) {
+	h.logger.Info("Html2Image - Started")
+	var request dtos.ImageRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	response, err := h.html2PdfService.HtmlToImage(request)
+
+	if renderFailed(c, err) {
+		return
+	}
+
+	if len(response.Content) == 0 {
+		c.JSON(500, dtos.WithError("Timeout", 40))
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("html converted successfully", 200, response))
+	h.logger.Info("Html2Image - Finished")
+}
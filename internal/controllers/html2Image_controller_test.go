@@ -0,0 +1,67 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHtml2Image(t *testing.T) {
+	t.Parallel()
+
+	logger := logger.NewFakeLogger()
+	hc := controllers.NewHtml2PdfController(logger)
+
+	serve := func(obj dtos.ImageRequest) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.POST("/html2image", hc.HandleHtml2Image)
+
+		body, _ := json.Marshal(obj)
+		req, _ := http.NewRequest("POST", "/html2image", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("HandleHtml2Image", func(t *testing.T) {
+		obj := dtos.ImageRequest{Format: dtos.ImageFormatJpeg, Quality: 80, FullPage: true}
+		obj.Content = jsonContent
+
+		w := serve(obj)
+
+		assert.NotEqual(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2ImageInvalidFormat", func(t *testing.T) {
+		obj := dtos.ImageRequest{Format: "gif"}
+		obj.Content = jsonContent
+
+		w := serve(obj)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2ImageSelectorAndFullPage", func(t *testing.T) {
+		obj := dtos.ImageRequest{FullPage: true, Selector: "h1"}
+		obj.Content = jsonContent
+
+		w := serve(obj)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2ImageWithoutContent", func(t *testing.T) {
+		w := serve(dtos.ImageRequest{})
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
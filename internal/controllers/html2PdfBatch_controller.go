@@ -0,0 +1,57 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"go.uber.org/zap"
+)
+
+const mimeZip = "application/zip"
+
+// @Summary API Convert a batch of html to pdf
+// @Description Render the items concurrently and stream a ZIP archive of their pdfs, each named after
+// @Description its Filename, ending with a manifest.json listing the dtos.BatchItemResult of every
+// @Description item. A failed item is reported in the manifest without failing the batch.
+// @Tags HTML PDF
+// @Accept json
+// @Produce application/zip,json
+// @Version 1.0
+// @Param Request body dtos.BatchRequest true "The input BatchRequest struct"
+// @Success 200 {file} binary "ZIP archive of the pdfs and manifest.json"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Router /v1/html2pdf/batch [post]
+func (h *Http2PdfController) HandleHtml2PdfBatch(c *gin.Context) {
+	h.logger.Info("Html2PdfBatch - Started")
+	var request dtos.BatchRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	if err := h.batchService.Check(request.Items); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	w := newAttachmentWriter(c, mimeZip, "batch.zip")
+	results, err := h.batchService.Render(c.Request.Context(), request.Items, w)
+
+	if err != nil {
+		h.logger.Error("Html2PdfBatch - Stream aborted", zap.Error(err))
+		if w.started {
+			abortStream(c)
+			return
+		}
+		c.JSON(500, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Success {
+			failed++
+		}
+	}
+	h.logger.Info("Html2PdfBatch - Finished", zap.Int("items", len(results)), zap.Int("failed", failed))
+}
@@ -0,0 +1,76 @@
+package controllers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHtml2PdfBatch(t *testing.T) {
+	t.Parallel()
+
+	logger := logger.NewFakeLogger()
+	hc := controllers.NewHtml2PdfController(logger)
+
+	serve := func(body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.POST("/html2pdf/batch", hc.HandleHtml2PdfBatch)
+
+		req, _ := http.NewRequest("POST", "/html2pdf/batch", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("HandleHtml2PdfBatch", func(t *testing.T) {
+		body, _ := json.Marshal(dtos.BatchRequest{Items: []dtos.HtmlRequest{
+			{Content: jsonContent, Filename: "first"},
+			{Content: jsonContent, Filename: "second"},
+		}})
+
+		w := serve(body)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "batch.zip")
+
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		assert.NoError(t, err)
+		if assert.NotEmpty(t, archive.File) {
+			manifest := archive.File[len(archive.File)-1]
+			assert.Equal(t, services.BatchManifest, manifest.Name)
+
+			rc, _ := manifest.Open()
+			defer rc.Close()
+			var results []dtos.BatchItemResult
+			assert.NoError(t, json.NewDecoder(rc).Decode(&results))
+			if assert.Len(t, results, 2) {
+				assert.Equal(t, "first.pdf", results[0].Filename)
+				assert.Equal(t, "second.pdf", results[1].Filename)
+			}
+		}
+	})
+
+	t.Run("HandleHtml2PdfBatchEmpty", func(t *testing.T) {
+		w := serve([]byte(`{"Items":[]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2PdfBatchInvalidItem", func(t *testing.T) {
+		w := serve([]byte(`{"Items":[{}]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/spreadsheet"
+	"go.uber.org/zap"
+)
+
+// mailMergeDataField is the multipart field holding the CSV or XLSX file
+// of a mail merge.
+const mailMergeDataField = "data"
+
+// @Summary API Mail merge html with the rows of a CSV or XLSX file
+// @Description Render the document once per row of the file uploaded in the data field, the first row
+// @Description naming the columns. The {{column}} placeholders of the content are filled with the values
+// @Description of the row, HTML escaped, and a template gets them as its data. The form carries the
+// @Description document and its assets as by /v1/html2pdf, and the dtos.MailMergeRequest options in the
+// @Description request field. With the pdf output the documents are merged, with a bookmark per row, the
+// @Description failed rows being left out and listed in the X-Failed-Rows header of a raw pdf. With the
+// @Description zip output the archive holds the pdf of each row, named by the FilenamePattern, and a
+// @Description manifest.json reporting the outcome of every row.
+// @Tags HTML PDF
+// @Accept mpfd
+// @Produce json,application/pdf,application/zip
+// @Version 1.0
+// @Param request formData string true "The MailMergeRequest options, as JSON"
+// @Param data formData file true "The CSV or XLSX file"
+// @Param binary query bool false "Return the raw pdf"
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 413 {object} dtos.BaseResponse "pdf too large"
+// @Failure 422 {object} dtos.BaseResponse "every row failed"
+// @Router /v1/html2pdf/mailmerge [post]
+func (h *Http2PdfController) HandleHtml2PdfMailMerge(c *gin.Context) {
+	h.logger.Info("Html2PdfMailMerge - Started")
+	var request dtos.MailMergeRequest
+
+	if c.ContentType() != binding.MIMEMultipartPOSTForm {
+		c.JSON(400, dtos.WithError("a multipart form is expected", 40))
+		return
+	}
+	if err := bindForm(c, &request, &request.HtmlRequest, h.maxUploadSize); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+	data, ok := request.Assets[mailMergeDataField]
+	if !ok {
+		c.JSON(400, dtos.WithError("the data field is missing", 40))
+		return
+	}
+	delete(request.Assets, mailMergeDataField)
+
+	var delimiter rune
+	if request.Delimiter != "" {
+		delimiter, _ = utf8.DecodeRuneInString(request.Delimiter)
+	}
+	rows, err := spreadsheet.Read(data, spreadsheet.Options{Sheet: request.Sheet, Delimiter: delimiter, MaxSize: h.maxUploadSize})
+	if err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	if request.Output == dtos.MailMergeZip {
+		w := newAttachmentWriter(c, mimeZip, attachmentName(request.Filename, "mailmerge.zip", mimeZip))
+		results, err := h.batchService.MailMerge(c.Request.Context(), request, rows, w)
+		if err != nil && w.started {
+			h.logger.Error("Html2PdfMailMerge - Stream aborted", zap.Error(err))
+			abortStream(c)
+			return
+		}
+		if renderFailed(c, err) {
+			return
+		}
+		h.logger.Info("Html2PdfMailMerge - Finished", zap.Int("rows", len(results)), zap.Int("failed", len(failedRows(results))))
+		return
+	}
+
+	var buf bytes.Buffer
+	results, err := h.batchService.MailMerge(c.Request.Context(), request, rows, &buf)
+	if renderFailed(c, err) {
+		return
+	}
+	failed := failedRows(results)
+	if buf.Len() == 0 {
+		errs := make([]dtos.Error, len(results))
+		for i, result := range results {
+			errs[i] = dtos.Error{Title: "row " + strconv.Itoa(result.Row), Detail: result.Error}
+		}
+		c.JSON(422, dtos.WithError("no row could be rendered", 42, errs...))
+		return
+	}
+
+	if wantsBinary(c, mimePDF) {
+		if len(failed) > 0 {
+			c.Header("X-Failed-Rows", strings.Join(failed, ","))
+		}
+		w := newAttachmentWriter(c, mimePDF, attachmentName(request.Filename, h.pdfFilename, mimePDF))
+		w.Write(buf.Bytes())
+	} else {
+		response := dtos.MailMergeResponse{PdfResponse: dtos.PdfResponse{Content: buf.Bytes()}, Rows: results}
+		c.JSON(200, dtos.WithSuccess("html merged successfully", 200, response))
+	}
+	h.logger.Info("Html2PdfMailMerge - Finished", zap.Int("rows", len(results)), zap.Int("failed", len(failed)))
+}
+
+// failedRows lists the rows of the results that failed.
+func failedRows(results []dtos.BatchItemResult) []string {
+	failed := []string{}
+	for _, result := range results {
+		if !result.Success {
+			failed = append(failed, strconv.Itoa(result.Row))
+		}
+	}
+	return failed
+}
@@ -0,0 +1,100 @@
+package controllers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHtml2PdfMailMerge(t *testing.T) {
+	t.Parallel()
+
+	logger := logger.NewFakeLogger()
+	hc := controllers.NewHtml2PdfController(logger)
+
+	serve := func(contentType string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.POST("/html2pdf/mailmerge", hc.HandleHtml2PdfMailMerge)
+
+		req, _ := http.NewRequest("POST", "/html2pdf/mailmerge", bytes.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		r.ServeHTTP(w, req)
+		return w
+	}
+	form := func(options string, content string, data string) (string, []byte) {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		form.WriteField("request", options)
+		part, _ := form.CreateFormFile("index.html", "index.html")
+		part.Write([]byte(content))
+		if data != "" {
+			part, _ = form.CreateFormFile("data", "staff.csv")
+			part.Write([]byte(data))
+		}
+		form.Close()
+		return form.FormDataContentType(), body.Bytes()
+	}
+
+	t.Run("HandleHtml2PdfMailMergeZip", func(t *testing.T) {
+		w := serve(form(`{"Output": "zip", "FilenamePattern": "contract-{{ id }}"}`, "<p>{{ name }}</p>", "id;name\n7;Ann\n9;Bob\n"))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/zip", w.Header().Get("Content-Type"))
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "mailmerge.zip")
+
+		archive, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+		assert.NoError(t, err)
+		if assert.NotEmpty(t, archive.File) {
+			manifest := archive.File[len(archive.File)-1]
+			assert.Equal(t, services.BatchManifest, manifest.Name)
+
+			rc, _ := manifest.Open()
+			defer rc.Close()
+			var results []dtos.BatchItemResult
+			assert.NoError(t, json.NewDecoder(rc).Decode(&results))
+			if assert.Len(t, results, 2) {
+				assert.Equal(t, 2, results[0].Row)
+				assert.Equal(t, "contract-7.pdf", results[0].Filename)
+				assert.Equal(t, 3, results[1].Row)
+				assert.Equal(t, "contract-9.pdf", results[1].Filename)
+			}
+		}
+	})
+
+	t.Run("HandleHtml2PdfMailMergeUnknownColumn", func(t *testing.T) {
+		w := serve(form(`{}`, "<p>{{ surname }}</p>", "id,name\n7,Ann\n"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), "surname")
+	})
+
+	t.Run("HandleHtml2PdfMailMergeWithoutData", func(t *testing.T) {
+		w := serve(form(`{}`, "<p>{{ name }}</p>", ""))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2PdfMailMergeInvalidOutput", func(t *testing.T) {
+		w := serve(form(`{"Output": "docx"}`, "<p>{{ name }}</p>", "name\nAnn\n"))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2PdfMailMergeJson", func(t *testing.T) {
+		w := serve("application/json", []byte(`{"Content": "<p></p>"}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
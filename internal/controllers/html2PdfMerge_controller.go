@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// @Summary API Merge html sections into a pdf
+// @Description Render each section with its own print options, header and footer, and concatenate
+// @Description them into a single pdf with a bookmark per section. The page numbers of the headers
+// @Description and footers run through the whole document. The pdf is returned as a raw
+// @Description application/pdf attachment as by /v1/html2pdf on request.
+// @Tags HTML PDF
+// @Accept json
+// @Produce json,application/pdf
+// @Version 1.0
+// @Param Request body dtos.MergeRequest true "The input MergeRequest struct"
+// @Param binary query bool false "Return the raw pdf"
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 413 {object} dtos.BaseResponse "pdf too large"
+// @Failure 422 {object} dtos.BaseResponse "waitFor condition not met, or data not matching its schema"
+// @Failure 503 {object} dtos.BaseResponse "no browser available"
+// @Router /v1/html2pdf/merge [post]
+func (h *Http2PdfController) HandleHtml2PdfMerge(c *gin.Context) {
+	h.logger.Info("Html2PdfMerge - Started")
+	var request dtos.MergeRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	response, err := h.html2PdfService.HtmlToPdfMerge(c.Request.Context(), request)
+
+	if renderFailed(c, err) {
+		return
+	}
+
+	if wantsBinary(c, mimePDF) {
+		w := newAttachmentWriter(c, mimePDF, attachmentName(request.Filename, h.pdfFilename, mimePDF))
+		w.Write(response.Content)
+	} else {
+		c.JSON(200, dtos.WithSuccess("html merged successfully", 200, response))
+	}
+	h.logger.Info("Html2PdfMerge - Finished")
+}
@@ -0,0 +1,55 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandleHtml2PdfMerge(t *testing.T) {
+	t.Parallel()
+
+	logger := logger.NewFakeLogger()
+	hc := controllers.NewHtml2PdfController(logger)
+
+	serve := func(body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.POST("/html2pdf/merge", hc.HandleHtml2PdfMerge)
+
+		req, _ := http.NewRequest("POST", "/html2pdf/merge", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("HandleHtml2PdfMerge", func(t *testing.T) {
+		section := dtos.MergeSection{Title: "Cover"}
+		section.Content = jsonContent
+		body, _ := json.Marshal(dtos.MergeRequest{Sections: []dtos.MergeSection{section, section}})
+
+		w := serve(body)
+
+		assert.NotEqual(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2PdfMergeEmpty", func(t *testing.T) {
+		w := serve([]byte(`{"Sections":[]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHtml2PdfMergeInvalidSection", func(t *testing.T) {
+		w := serve([]byte(`{"Sections":[{"Title":"Cover"}]}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
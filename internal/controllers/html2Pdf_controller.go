@@ -2,19 +2,26 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"runtime"
 
 	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/configs"
 	"github.com/kolzxx/html2pdf/internal/dtos"
 	"github.com/kolzxx/html2pdf/internal/interfaces"
 	"github.com/kolzxx/html2pdf/internal/logger"
 	"github.com/kolzxx/html2pdf/internal/services"
+	"go.uber.org/zap"
 )
 
 type Http2PdfController struct {
 	html2PdfService interfaces.Html2PdfServiceInterface
+	jobService      interfaces.JobServiceInterface
+	batchService    interfaces.BatchServiceInterface
 	chromedpService *services.ChromedpService
 	logger          logger.Logger
+	maxUploadSize   int64
+	pdfFilename     string
 }
 
 func NewHtml2PdfController(logger logger.Logger) *Http2PdfController {
@@ -23,6 +30,8 @@ func NewHtml2PdfController(logger logger.Logger) *Http2PdfController {
 
 	var app Http2PdfController
 	app.logger = logger
+	app.maxUploadSize = configs.GetConfig().Server.MaxUploadSize
+	app.pdfFilename = configs.GetConfig().Pdf.Filename
 	app.chromedpService = services.NewChromedpService(context.Background(), logger)
 	err := app.chromedpService.RunChromeDp()
 	if err != nil {
@@ -30,31 +39,60 @@ func NewHtml2PdfController(logger logger.Logger) *Http2PdfController {
 	}
 
 	app.html2PdfService = services.NewHtml2PdfService(logger, app.chromedpService)
+	app.jobService = services.NewJobService(context.Background(), logger, app.html2PdfService)
+	app.batchService = services.NewBatchService(logger, app.html2PdfService)
 	return &app
 }
 
+// JobService is the service running the asynchronous renders of the
+// controller.
+func (h *Http2PdfController) JobService() interfaces.JobServiceInterface {
+	return h.jobService
+}
+
 // @Summary API Convert html to pdf
-// @Description Retrieve the pdf file of a html
+// @Description Retrieve the pdf file of a html. Besides JSON, the request can be a multipart form
+// @Description with an index.html file, its assets named by relative path and the JSON options in
+// @Description the request field, or a ZIP bundle holding index.html, its assets and request.json.
+// @Description The pdf is returned as a raw application/pdf attachment instead of the JSON envelope
+// @Description when the Accept header prefers application/pdf or the binary query flag is set.
+// @Description With a CallbackURL the request is answered at once with a job, whose outcome is
+// @Description posted to the URL as a signed dtos.WebhookEvent.
 // @Tags HTML PDF
-// @Produce json
+// @Accept json,mpfd,application/zip
+// @Produce json,application/pdf
 // @Version 1.0
 // @Param Request body dtos.HtmlRequest true "The input HtmlRequest struct"
+// @Param binary query bool false "Return the raw pdf"
 // @Success 200 {object} dtos.BaseResponse "success"
+// @Success 202 {object} dtos.BaseResponse "job queued, outcome posted to the CallbackURL"
 // @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 413 {object} dtos.BaseResponse "pdf too large"
+// @Failure 422 {object} dtos.BaseResponse "waitFor condition not met, or data not matching its schema"
+// @Failure 503 {object} dtos.BaseResponse "no browser available"
 // @Router /v1/html2pdf [post]
 func (h *Http2PdfController) HandleHttp2Pdf(c *gin.Context) {
 	h.logger.Info("Http2Pdf - Started")
 	var request dtos.HtmlRequest
 
-	if err := c.ShouldBindJSON(&request); err != nil {
+	if err := bindHtmlRequest(c, &request, h.maxUploadSize); err != nil {
 		c.JSON(400, dtos.WithError(err.Error(), 40))
 		return
 	}
 
+	if request.CallbackURL != "" {
+		submitJob(c, h.jobService, request)
+		return
+	}
+
+	if wantsBinary(c, mimePDF) {
+		h.streamPdf(c, request)
+		return
+	}
+
 	response, err := h.html2PdfService.HtmlToPdf(request)
 
-	if err != nil {
-		c.JSON(500, dtos.WithError(err.Error(), 40))
+	if renderFailed(c, err) {
 		return
 	}
 
@@ -66,3 +104,78 @@ func (h *Http2PdfController) HandleHttp2Pdf(c *gin.Context) {
 	c.JSON(200, dtos.WithSuccess("html converted successfully", 200, response))
 	h.logger.Info("Http2Pdf - Finished")
 }
+
+// streamPdf writes the pdf to the response as it is printed. Errors met
+// before the first chunk get the usual JSON response; after it the
+// connection is dropped so the client cannot take a truncated document
+// for a complete one.
+func (h *Http2PdfController) streamPdf(c *gin.Context, request dtos.HtmlRequest) {
+	w := newAttachmentWriter(c, mimePDF, attachmentName(request.Filename, h.pdfFilename, mimePDF))
+	_, err := h.html2PdfService.HtmlToPdfStream(request, w)
+
+	if w.started {
+		if err != nil {
+			h.logger.Error("Http2Pdf - Stream aborted", zap.Error(err))
+			abortStream(c)
+			return
+		}
+		h.logger.Info("Http2Pdf - Finished")
+		return
+	}
+
+	if renderFailed(c, err) {
+		return
+	}
+
+	c.JSON(500, dtos.WithError("Timeout", 40))
+}
+
+// renderFailed writes the response matching a render error, telling
+// whether there was one.
+func renderFailed(c *gin.Context, err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, services.ErrInvalidRequest) {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return true
+	}
+
+	if errors.Is(err, services.ErrOutputTooLarge) {
+		c.JSON(413, dtos.WithError(err.Error(), 41))
+		return true
+	}
+
+	if errors.Is(err, services.ErrRenderTimeout) {
+		c.JSON(500, dtos.WithError("Timeout", 40))
+		return true
+	}
+
+	if errors.Is(err, services.ErrPoolUnavailable) || errors.Is(err, services.ErrBrowserCrashed) || errors.Is(err, services.ErrRegistryUnavailable) {
+		c.JSON(503, dtos.WithError(err.Error(), 50))
+		return true
+	}
+
+	var schemaErr *services.SchemaError
+	if errors.As(err, &schemaErr) {
+		violations := make([]dtos.Error, len(schemaErr.Violations))
+		for i, violation := range schemaErr.Violations {
+			violations[i] = dtos.Error{Title: violation.Pointer, Detail: violation.Message}
+		}
+		c.JSON(422, dtos.WithError("data does not match the schema", 43, violations...))
+		return true
+	}
+
+	var waitErr *services.WaitError
+	if errors.As(err, &waitErr) {
+		c.JSON(422, dtos.WithError("page not ready to print", 42, dtos.Error{
+			Title:  "waitFor." + waitErr.Condition,
+			Detail: err.Error(),
+		}))
+		return true
+	}
+
+	c.JSON(500, dtos.WithError(err.Error(), 40))
+	return true
+}
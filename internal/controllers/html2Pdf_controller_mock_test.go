@@ -0,0 +1,7 @@
+package controllers
+
+var WantsBinary = wantsBinary
+var AttachmentName = attachmentName
+var NewAttachmentWriter = newAttachmentWriter
+var RenderFailed = renderFailed
+var AbortStream = abortStream
@@ -3,6 +3,8 @@ package controllers_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -12,6 +14,7 @@ import (
 	"github.com/kolzxx/html2pdf/internal/controllers"
 	"github.com/kolzxx/html2pdf/internal/dtos"
 	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -91,4 +94,168 @@ func TestHandleHtml2Pdf(t *testing.T) {
 		assert.NotEqual(t, respJson, string(responseData))
 	})
 
+	t.Run("HandleHttp2PdfContentAndUrl", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+
+		hc := controllers.NewHtml2PdfController(logger)
+
+		path := "/html2pdf"
+		r.POST(path, hc.HandleHttp2Pdf)
+
+		obj := dtos.HtmlRequest{}
+		obj.Content = jsonContent
+		obj.URL = "https://example.com/"
+
+		body, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHttp2PdfUrlNotAllowed", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+
+		hc := controllers.NewHtml2PdfController(logger)
+
+		path := "/html2pdf"
+		r.POST(path, hc.HandleHttp2Pdf)
+
+		obj := dtos.HtmlRequest{}
+		obj.URL = "https://example.com/"
+
+		body, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req, _ := http.NewRequest("POST", path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleHttp2PdfTemplate", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+		hc := controllers.NewHtml2PdfController(logger)
+
+		for name, tc := range map[string]struct {
+			obj  dtos.HtmlRequest
+			code int
+		}{
+			"TemplateAndContent": {dtos.HtmlRequest{Template: "<p>{{.Name}}</p>", Content: jsonContent}, http.StatusBadRequest},
+			"TemplateInvalid":    {dtos.HtmlRequest{Template: "<p>{{.Name</p>"}, http.StatusBadRequest},
+			"TemplateUnknown":    {dtos.HtmlRequest{TemplateName: "missing"}, http.StatusBadRequest},
+		} {
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.POST("/html2pdf", hc.HandleHttp2Pdf)
+
+			body, _ := json.Marshal(tc.obj)
+			req, _ := http.NewRequest("POST", "/html2pdf", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.code, w.Code, name)
+		}
+	})
+
+	t.Run("HandleHttp2PdfSchema", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+		hc := controllers.NewHtml2PdfController(logger)
+		schema := map[string]interface{}{
+			"type":     "object",
+			"required": []interface{}{"name", "amount"},
+			"properties": map[string]interface{}{
+				"amount": map[string]interface{}{"type": "number", "minimum": 0},
+			},
+		}
+
+		for name, tc := range map[string]struct {
+			obj        dtos.HtmlRequest
+			code       int
+			violations int
+		}{
+			"Invalid":       {dtos.HtmlRequest{Content: "<p>{{name}}</p>", Schema: schema, Data: map[string]interface{}{"amount": -1}}, http.StatusUnprocessableEntity, 2},
+			"Placeholder":   {dtos.HtmlRequest{Content: "<p>{{customer.name}}</p>", Data: map[string]interface{}{}}, http.StatusUnprocessableEntity, 1},
+			"SchemaBroken":  {dtos.HtmlRequest{Content: jsonContent, Schema: map[string]interface{}{"type": 1}}, http.StatusBadRequest, 1},
+			"SchemaUnknown": {dtos.HtmlRequest{Content: jsonContent, SchemaName: "missing"}, http.StatusBadRequest, 1},
+		} {
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.POST("/html2pdf", hc.HandleHttp2Pdf)
+
+			body, _ := json.Marshal(tc.obj)
+			req, _ := http.NewRequest("POST", "/html2pdf", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			var response dtos.BaseResponse
+			json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, tc.code, w.Code, name)
+			if tc.code == http.StatusUnprocessableEntity {
+				assert.Len(t, response.Errors, tc.violations, name)
+			}
+		}
+	})
+
+	t.Run("RenderFailed", func(t *testing.T) {
+		for name, tc := range map[string]struct {
+			err     error
+			code    int
+			message string
+		}{
+			"Timeout":  {services.ErrRenderTimeout, http.StatusInternalServerError, "Timeout"},
+			"Failed":   {fmt.Errorf("render failed: %w", errors.New("net::ERR_NAME_NOT_RESOLVED")), http.StatusInternalServerError, "net::ERR_NAME_NOT_RESOLVED"},
+			"Browser":  {services.ErrPoolUnavailable, http.StatusServiceUnavailable, services.ErrPoolUnavailable.Error()},
+			"Registry": {services.ErrRegistryUnavailable, http.StatusServiceUnavailable, services.ErrRegistryUnavailable.Error()},
+		} {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+
+			assert.True(t, controllers.RenderFailed(c, tc.err), name)
+			assert.Equal(t, tc.code, w.Code, name)
+			assert.Contains(t, w.Body.String(), tc.message, name)
+		}
+	})
+
+	t.Run("HandleHttp2PdfPrintOptions", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+		hc := controllers.NewHtml2PdfController(logger)
+
+		for name, tc := range map[string]struct {
+			body    string
+			message string
+		}{
+			"Scale":         {`{"Content": "<p></p>", "WithScale": 3}`, "WithScale must be between 0.1 and 2"},
+			"Unit":          {`{"Content": "<p></p>", "MarginTop": "10furlongs"}`, "invalid length"},
+			"Format":        {`{"Content": "<p></p>", "PaperFormat": "B5"}`, "unknown paper format"},
+			"FormatAndSize": {`{"Content": "<p></p>", "PaperFormat": "A4", "PaperWidth": "100mm"}`, "PaperWidth"},
+			"Margins":       {`{"Content": "<p></p>", "PaperFormat": "Thermal58", "MarginLeft": "30mm", "MarginRight": "30mm"}`, "leave no room"},
+			"Profile":       {`{"Content": "<p></p>", "Profile": "missing"}`, "unknown profile"},
+		} {
+			w := httptest.NewRecorder()
+			_, r := gin.CreateTestContext(w)
+			r.POST("/html2pdf", hc.HandleHttp2Pdf)
+
+			req, _ := http.NewRequest("POST", "/html2pdf", bytes.NewReader([]byte(tc.body)))
+			req.Header.Set("Content-Type", "application/json")
+			r.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, name)
+			assert.Contains(t, w.Body.String(), tc.message, name)
+		}
+	})
 }
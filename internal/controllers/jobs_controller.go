@@ -0,0 +1,130 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
+)
+
+type JobsController struct {
+	jobService    interfaces.JobServiceInterface
+	logger        logger.Logger
+	maxUploadSize int64
+	pdfFilename   string
+}
+
+func NewJobsController(logger logger.Logger, jobService interfaces.JobServiceInterface) *JobsController {
+	return &JobsController{
+		jobService:    jobService,
+		logger:        logger,
+		maxUploadSize: configs.GetConfig().Server.MaxUploadSize,
+		pdfFilename:   configs.GetConfig().Pdf.Filename,
+	}
+}
+
+// @Summary API Submit a html to pdf job
+// @Description Queue the conversion of a html, accepted as by /v1/html2pdf, and return the job to poll
+// @Tags JOBS
+// @Accept json,mpfd,application/zip
+// @Produce json
+// @Version 1.0
+// @Param Request body dtos.HtmlRequest true "The input HtmlRequest struct"
+// @Success 202 {object} dtos.BaseResponse "job queued"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 503 {object} dtos.BaseResponse "job queue full"
+// @Router /v1/jobs [post]
+func (h *JobsController) HandleCreateJob(c *gin.Context) {
+	var request dtos.HtmlRequest
+
+	if err := bindHtmlRequest(c, &request, h.maxUploadSize); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	submitJob(c, h.jobService, request)
+}
+
+// submitJob queues the request and answers with the job. The correlation
+// ID of the request travels with the job callback.
+func submitJob(c *gin.Context, jobService interfaces.JobServiceInterface, request dtos.HtmlRequest) {
+	job, err := jobService.Submit(request, c.Request.Header.Get("X-Correlation-ID"))
+	if errors.Is(err, services.ErrInvalidRequest) {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+	if err != nil {
+		c.JSON(503, dtos.WithError(err.Error(), 50))
+		return
+	}
+
+	c.Header("Location", "/v1/jobs/"+job.ID)
+	c.JSON(202, dtos.WithSuccess("job queued", 202, job))
+}
+
+// @Summary API Get a job
+// @Description Retrieve the status of a job: queued, rendering, done, failed or canceled
+// @Tags JOBS
+// @Produce json
+// @Version 1.0
+// @Param id path string true "Job ID"
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Failure 404 {object} dtos.BaseResponse "job not found"
+// @Router /v1/jobs/{id} [get]
+func (h *JobsController) HandleGetJob(c *gin.Context) {
+	job, err := h.jobService.Get(c.Param("id"))
+	if err != nil {
+		c.JSON(404, dtos.WithError(err.Error(), 44))
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("job found", 200, job))
+}
+
+// @Summary API Get the pdf of a job
+// @Description Retrieve the pdf of a done job as an application/pdf attachment
+// @Tags JOBS
+// @Produce application/pdf,json
+// @Version 1.0
+// @Param id path string true "Job ID"
+// @Success 200 {file} binary "the pdf"
+// @Failure 404 {object} dtos.BaseResponse "job not found"
+// @Failure 409 {object} dtos.BaseResponse "job not done"
+// @Router /v1/jobs/{id}/result [get]
+func (h *JobsController) HandleGetJobResult(c *gin.Context) {
+	job, content, err := h.jobService.Result(c.Param("id"))
+	if errors.Is(err, services.ErrJobNotDone) {
+		c.JSON(409, dtos.WithError(err.Error()+": "+job.Status, 49))
+		return
+	}
+	if err != nil {
+		c.JSON(404, dtos.WithError(err.Error(), 44))
+		return
+	}
+
+	w := newAttachmentWriter(c, mimePDF, attachmentName(job.Filename, h.pdfFilename, mimePDF))
+	w.Write(content)
+}
+
+// @Summary API Cancel a job
+// @Description Cancel a queued or rendering job, or delete a finished one along with its pdf
+// @Tags JOBS
+// @Produce json
+// @Version 1.0
+// @Param id path string true "Job ID"
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Failure 404 {object} dtos.BaseResponse "job not found"
+// @Router /v1/jobs/{id} [delete]
+func (h *JobsController) HandleDeleteJob(c *gin.Context) {
+	job, err := h.jobService.Cancel(c.Param("id"))
+	if err != nil {
+		c.JSON(404, dtos.WithError(err.Error(), 44))
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("job canceled", 200, job))
+}
@@ -0,0 +1,69 @@
+package controllers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobsController(t *testing.T) {
+	t.Parallel()
+
+	logger := logger.NewFakeLogger()
+	hc := controllers.NewHtml2PdfController(logger)
+	jc := controllers.NewJobsController(logger, hc.JobService())
+
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	r.POST("/jobs", jc.HandleCreateJob)
+	r.GET("/jobs/:id", jc.HandleGetJob)
+	r.GET("/jobs/:id/result", jc.HandleGetJobResult)
+	r.DELETE("/jobs/:id", jc.HandleDeleteJob)
+
+	serve := func(method string, path string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("HandleCreateJob", func(t *testing.T) {
+		body, _ := json.Marshal(dtos.HtmlRequest{Content: jsonContent})
+
+		w := serve("POST", "/jobs", body)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+
+		var response struct{ Result dtos.Job }
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "/v1/jobs/"+response.Result.ID, w.Header().Get("Location"))
+
+		w = serve("GET", "/jobs/"+response.Result.ID, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serve("DELETE", "/jobs/"+response.Result.ID, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = serve("GET", "/jobs/"+response.Result.ID+"/result", nil)
+		assert.Contains(t, []int{http.StatusConflict, http.StatusNotFound}, w.Code)
+	})
+
+	t.Run("HandleCreateJobInvalid", func(t *testing.T) {
+		w := serve("POST", "/jobs", []byte(`{}`))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HandleGetJobNotFound", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/jobs/missing", nil).Code)
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/jobs/missing/result", nil).Code)
+		assert.Equal(t, http.StatusNotFound, serve("DELETE", "/jobs/missing", nil).Code)
+	})
+}
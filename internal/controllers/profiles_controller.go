@@ -0,0 +1,41 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
+)
+
+type ProfilesController struct {
+	profileService interfaces.ProfileServiceInterface
+	logger         logger.Logger
+}
+
+func NewProfilesController(logger logger.Logger, profileService interfaces.ProfileServiceInterface) *ProfilesController {
+	return &ProfilesController{
+		profileService: profileService,
+		logger:         logger,
+	}
+}
+
+// @Summary API List the rendering profiles
+// @Description Retrieve the rendering profiles of the server with their settings. A request names one
+// @Description in its Profile field to take the print options, header and footer templates and CSS it
+// @Description leaves out from the profile.
+// @Tags PROFILES
+// @Produce json
+// @Version 1.0
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Router /v1/profiles [get]
+func (h *ProfilesController) HandleListProfiles(c *gin.Context) {
+	profiles, err := h.profileService.List()
+	if err != nil {
+		h.logger.Error("ListProfiles - Failed", zap.Error(err))
+		c.JSON(500, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("profiles found", 200, profiles))
+}
@@ -0,0 +1,60 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/stretchr/testify/assert"
+)
+
+// profileServiceStub lists its profiles, or fails with err.
+type profileServiceStub struct {
+	profiles []dtos.Profile
+	err      error
+}
+
+func (s profileServiceStub) List() ([]dtos.Profile, error) {
+	return s.profiles, s.err
+}
+
+func TestProfilesController(t *testing.T) {
+	t.Parallel()
+
+	serve := func(service profileServiceStub) *httptest.ResponseRecorder {
+		pc := controllers.NewProfilesController(logger.NewFakeLogger(), service)
+		w := httptest.NewRecorder()
+		_, r := gin.CreateTestContext(w)
+		r.GET("/profiles", pc.HandleListProfiles)
+
+		req, _ := http.NewRequest("GET", "/profiles", nil)
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("HandleListProfiles", func(t *testing.T) {
+		w := serve(profileServiceStub{profiles: []dtos.Profile{{Name: "contract-a4", PrintOptions: dtos.PrintOptions{PaperFormat: "A4"}}}})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Result []dtos.Profile
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		if assert.Len(t, response.Result, 1) {
+			assert.Equal(t, "contract-a4", response.Result[0].Name)
+			assert.Equal(t, "A4", response.Result[0].PaperFormat)
+		}
+	})
+
+	t.Run("HandleListProfilesFailed", func(t *testing.T) {
+		w := serve(profileServiceStub{err: errors.New("permission denied")})
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
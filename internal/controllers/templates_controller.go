@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
+)
+
+type TemplatesController struct {
+	templateService interfaces.TemplateServiceInterface
+	logger          logger.Logger
+}
+
+func NewTemplatesController(logger logger.Logger, templateService interfaces.TemplateServiceInterface) *TemplatesController {
+	return &TemplatesController{
+		templateService: templateService,
+		logger:          logger,
+	}
+}
+
+// @Summary API List the templates
+// @Description Retrieve the templates of the registry, with their version numbers
+// @Tags TEMPLATES
+// @Produce json
+// @Version 1.0
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Router /v1/templates [get]
+func (h *TemplatesController) HandleListTemplates(c *gin.Context) {
+	templates, err := h.templateService.List()
+	if templateFailed(c, err) {
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("templates found", 200, templates))
+}
+
+// @Summary API Create a template
+// @Description Store a html/template document, layout or partial as the version 1 of a new template.
+// @Description A document renders within its layout, which shows it with {{template "content" .}},
+// @Description and its partials and the ones of its layout are available under their ID. The
+// @Description layout and partials are referenced by ID, optionally followed by @version. Render
+// @Description requests then reference the document in TemplateName as ID or ID@version.
+// @Tags TEMPLATES
+// @Accept json
+// @Produce json
+// @Version 1.0
+// @Param Request body dtos.TemplateRequest true "The input TemplateRequest struct"
+// @Success 201 {object} dtos.BaseResponse "template created"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 409 {object} dtos.BaseResponse "template already exists"
+// @Router /v1/templates [post]
+func (h *TemplatesController) HandleCreateTemplate(c *gin.Context) {
+	var request dtos.TemplateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	template, err := h.templateService.Create(request)
+	if templateFailed(c, err) {
+		return
+	}
+
+	c.Header("Location", "/v1/templates/"+template.ID)
+	c.JSON(201, dtos.WithSuccess("template created", 201, template))
+}
+
+// @Summary API Get a template
+// @Description Retrieve a template with its latest version, or the version given as ID@version
+// @Tags TEMPLATES
+// @Produce json
+// @Version 1.0
+// @Param id path string true "Template ID"
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 404 {object} dtos.BaseResponse "template not found"
+// @Router /v1/templates/{id} [get]
+func (h *TemplatesController) HandleGetTemplate(c *gin.Context) {
+	template, err := h.templateService.Get(c.Param("id"))
+	if templateFailed(c, err) {
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("template found", 200, template))
+}
+
+// @Summary API Get a template version
+// @Description Retrieve a template with one of its versions
+// @Tags TEMPLATES
+// @Produce json
+// @Version 1.0
+// @Param id path string true "Template ID"
+// @Param version path int true "Template version"
+// @Success 200 {object} dtos.BaseResponse "success"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 404 {object} dtos.BaseResponse "template not found"
+// @Router /v1/templates/{id}/versions/{version} [get]
+func (h *TemplatesController) HandleGetTemplateVersion(c *gin.Context) {
+	template, err := h.templateService.Get(c.Param("id") + "@" + c.Param("version"))
+	if templateFailed(c, err) {
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("template found", 200, template))
+}
+
+// @Summary API Update a template
+// @Description Store a new version of a template, the previous ones remaining available. The kind
+// @Description of a template cannot change.
+// @Tags TEMPLATES
+// @Accept json
+// @Produce json
+// @Version 1.0
+// @Param id path string true "Template ID"
+// @Param Request body dtos.TemplateRequest true "The input TemplateRequest struct"
+// @Success 200 {object} dtos.BaseResponse "template updated"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 404 {object} dtos.BaseResponse "template not found"
+// @Router /v1/templates/{id} [put]
+func (h *TemplatesController) HandleUpdateTemplate(c *gin.Context) {
+	var request dtos.TemplateRequest
+
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+		return
+	}
+
+	template, err := h.templateService.Update(c.Param("id"), request)
+	if templateFailed(c, err) {
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("template updated", 200, template))
+}
+
+// @Summary API Delete a template
+// @Description Delete a template along with all its versions. Layouts and partials other templates
+// @Description still use cannot be deleted.
+// @Tags TEMPLATES
+// @Produce json
+// @Version 1.0
+// @Param id path string true "Template ID"
+// @Success 200 {object} dtos.BaseResponse "template deleted"
+// @Failure 400 {object} dtos.BaseResponse "error"
+// @Failure 404 {object} dtos.BaseResponse "template not found"
+// @Failure 409 {object} dtos.BaseResponse "template in use"
+// @Router /v1/templates/{id} [delete]
+func (h *TemplatesController) HandleDeleteTemplate(c *gin.Context) {
+	if templateFailed(c, h.templateService.Delete(c.Param("id"))) {
+		return
+	}
+
+	c.JSON(200, dtos.WithSuccess("template deleted", 200, nil))
+}
+
+// templateFailed answers with the status of the error of a registry
+// operation, reporting whether there was one.
+func templateFailed(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, services.ErrInvalidRequest):
+		c.JSON(400, dtos.WithError(err.Error(), 40))
+	case errors.Is(err, services.ErrTemplateNotFound):
+		c.JSON(404, dtos.WithError(err.Error(), 44))
+	case errors.Is(err, services.ErrTemplateExists), errors.Is(err, services.ErrTemplateInUse):
+		c.JSON(409, dtos.WithError(err.Error(), 49))
+	case errors.Is(err, services.ErrRegistryUnavailable):
+		c.JSON(503, dtos.WithError(err.Error(), 50))
+	default:
+		c.JSON(500, dtos.WithError(err.Error(), 40))
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package controllers_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// templateServiceStub knows the invoice template only.
+type templateServiceStub struct{}
+
+func (templateServiceStub) List() ([]dtos.Template, error) {
+	return []dtos.Template{{ID: "invoice"}}, nil
+}
+
+func (templateServiceStub) Get(ref string) (dtos.Template, error) {
+	switch ref {
+	case "invoice", "invoice@1":
+		return dtos.Template{ID: "invoice", Version: &dtos.TemplateVersion{Version: 1}}, nil
+	case "invoice@x":
+		return dtos.Template{}, fmt.Errorf("%w: invalid template version", services.ErrInvalidRequest)
+	}
+	return dtos.Template{}, services.ErrTemplateNotFound
+}
+
+func (templateServiceStub) Create(request dtos.TemplateRequest) (dtos.Template, error) {
+	if request.ID == "invoice" {
+		return dtos.Template{}, services.ErrTemplateExists
+	}
+	return dtos.Template{ID: request.ID}, nil
+}
+
+func (s templateServiceStub) Update(id string, request dtos.TemplateRequest) (dtos.Template, error) {
+	return s.Get(id)
+}
+
+func (s templateServiceStub) Delete(id string) error {
+	_, err := s.Get(id)
+	return err
+}
+
+func TestTemplatesController(t *testing.T) {
+	t.Parallel()
+
+	tc := controllers.NewTemplatesController(logger.NewFakeLogger(), templateServiceStub{})
+
+	_, r := gin.CreateTestContext(httptest.NewRecorder())
+	r.GET("/templates", tc.HandleListTemplates)
+	r.POST("/templates", tc.HandleCreateTemplate)
+	r.GET("/templates/:id", tc.HandleGetTemplate)
+	r.GET("/templates/:id/versions/:version", tc.HandleGetTemplateVersion)
+	r.PUT("/templates/:id", tc.HandleUpdateTemplate)
+	r.DELETE("/templates/:id", tc.HandleDeleteTemplate)
+
+	serve := func(method string, path string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("HandleCreateTemplate", func(t *testing.T) {
+		w := serve("POST", "/templates", `{"ID":"receipt","Content":"<p>{{.Total}}</p>"}`)
+
+		assert.Equal(t, http.StatusCreated, w.Code)
+		assert.Equal(t, "/v1/templates/receipt", w.Header().Get("Location"))
+	})
+
+	t.Run("HandleCreateTemplateInvalid", func(t *testing.T) {
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/templates", `{"ID":"receipt"}`).Code)
+		assert.Equal(t, http.StatusBadRequest, serve("POST", "/templates", `{"ID":"receipt","Kind":"page","Content":"x"}`).Code)
+		assert.Equal(t, http.StatusConflict, serve("POST", "/templates", `{"ID":"invoice","Content":"x"}`).Code)
+	})
+
+	t.Run("HandleGetTemplate", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("GET", "/templates", "").Code)
+		assert.Equal(t, http.StatusOK, serve("GET", "/templates/invoice", "").Code)
+		assert.Equal(t, http.StatusOK, serve("GET", "/templates/invoice/versions/1", "").Code)
+		assert.Equal(t, http.StatusBadRequest, serve("GET", "/templates/invoice/versions/x", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("GET", "/templates/missing", "").Code)
+	})
+
+	t.Run("HandleUpdateTemplate", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("PUT", "/templates/invoice", `{"Content":"x"}`).Code)
+		assert.Equal(t, http.StatusNotFound, serve("PUT", "/templates/missing", `{"Content":"x"}`).Code)
+	})
+
+	t.Run("HandleDeleteTemplate", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve("DELETE", "/templates/invoice", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("DELETE", "/templates/missing", "").Code)
+	})
+}
@@ -0,0 +1,19 @@
+package dtos
+
+// BatchRequest lists the documents of a batch, each one named after its
+// Filename in the returned ZIP archive.
+type BatchRequest struct {
+	Items []HtmlRequest `binding:"required,min=1,dive"`
+}
+
+// BatchItemResult is the entry of an item in the manifest.json of a batch
+// archive. The items of a mail merge come with the Row of the data file
+// they were built from.
+type BatchItemResult struct {
+	Index    int
+	Row      int `json:",omitempty"`
+	Filename string
+	Success  bool
+	Size     int    `json:",omitempty"`
+	Error    string `json:",omitempty"`
+}
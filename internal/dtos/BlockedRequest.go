@@ -0,0 +1,7 @@
+package dtos
+
+// BlockedRequest is a request of the page the network policy refused.
+type BlockedRequest struct {
+	URL    string
+	Reason string
+}
@@ -1,20 +1,50 @@
 package dtos
 
 type HtmlRequest struct {
-	PrintBackground     bool    `default:"false"`
-	PreferCSSPageSize   bool    `default:"false"`
-	DisplayHeaderFooter bool    `default:"true"`
-	Landscape           bool    `default:"false"`
-	MarginTop           float64 `default:"1.0"`
-	MarginBottom        float64 `default:"1.0"`
-	MarginRight         float64 `default:"0"`
-	MarginLeft          float64 `default:"1.0"`
-	PaperWidth          float64 `default:"8.27"`
-	PaperHeight         float64 `default:"11.69"`
-	WithScale           float64 `default:"0.57"`
-	Content             string  `binding:"required" `
-	ContentCss          string
-	HeaderTemplate      string
-	FooterTemplate      string
-	WaitElementId       string `binding:"required" `
+	PrintOptions
+	// Profile names a rendering profile of the server, whose settings
+	// apply to the ones the request leaves out.
+	Profile string
+	Content string `binding:"required_without_all=URL Template TemplateName"`
+	// URL is rendered instead of Content, which must then be empty. Its
+	// host must be allowed by the server configuration.
+	URL string `binding:"omitempty,url,excluded_with=Content"`
+	// Template, inline or from the registry under TemplateName, is
+	// executed with Data as html/template to build the content. The
+	// TemplateName is a template ID, optionally followed by @version.
+	Template     string `binding:"excluded_with=Content URL"`
+	TemplateName string `binding:"excluded_with=Content URL Template"`
+	Data         map[string]interface{}
+	// Data is validated against the JSON Schema, inline or stored on the
+	// server under SchemaName, before anything is rendered. Without a
+	// template, its values fill the {{field}} placeholders of Content,
+	// field being a dotted path such as customer.name or items.0.price.
+	Schema     map[string]interface{}
+	SchemaName string `binding:"excluded_with=Schema"`
+	// Headers and BasicAuth are sent along the requests to the URL origin.
+	Headers        map[string]string
+	BasicAuth      *BasicAuth
+	Cookies        []Cookie `binding:"dive"`
+	ContentCss     string
+	Stylesheets    []Stylesheet `binding:"dive"`
+	HeaderTemplate string
+	FooterTemplate string
+	WaitElementId  string
+	WaitFor        *WaitFor
+	Scripts        []Script `binding:"dive"`
+	// Inserts are existing pdfs placed into the rendered one, in order.
+	Inserts []PdfInsert `binding:"dive"`
+	// CallbackURL makes the render asynchronous: the request is answered
+	// with a job and its outcome is posted to the URL.
+	CallbackURL string `binding:"omitempty,url"`
+	// Filename names the pdf when it is returned as a binary attachment.
+	Filename string
+	// Offline blocks every request of the page but the ones to its assets.
+	Offline bool
+	// Assets are the files uploaded along with the content, by path.
+	Assets map[string][]byte `json:"-" swaggerignore:"true"`
+	// BaseStylesheets are the stylesheets of the template and the profile
+	// of the request, written before ContentCss for the request to
+	// override them.
+	BaseStylesheets []Stylesheet `json:"-" swaggerignore:"true"`
 }
@@ -0,0 +1,24 @@
+package dtos
+
+const (
+	ImageFormatPng  = "png"
+	ImageFormatJpeg = "jpeg"
+	ImageFormatWebp = "webp"
+)
+
+// ImageRequest loads the content as HtmlRequest does and takes a screenshot
+// of the page. The viewport is 1280x800 at a device scale factor of 1 by
+// default; Quality only applies to jpeg and webp, Transparent to png and
+// webp. FullPage captures the whole page instead of the viewport and
+// Selector clips the screenshot to the first element it matches.
+type ImageRequest struct {
+	HtmlRequest
+	Format            string  `binding:"omitempty,oneof=png jpeg webp" default:"png"`
+	Quality           int64   `binding:"omitempty,min=1,max=100"`
+	FullPage          bool    `default:"false"`
+	Selector          string  `binding:"excluded_with=FullPage"`
+	ViewportWidth     int64   `binding:"omitempty,min=1,max=10000" default:"1280"`
+	ViewportHeight    int64   `binding:"omitempty,min=1,max=10000" default:"800"`
+	DeviceScaleFactor float64 `binding:"omitempty,gt=0,max=4" default:"1"`
+	Transparent       bool    `default:"false"`
+}
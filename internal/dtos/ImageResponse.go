@@ -0,0 +1,8 @@
+package dtos
+
+type ImageResponse struct {
+	Content         []byte
+	ContentType     string
+	Scripts         []ScriptResult   `json:",omitempty"`
+	BlockedRequests []BlockedRequest `json:",omitempty"`
+}
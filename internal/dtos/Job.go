@@ -0,0 +1,25 @@
+package dtos
+
+import "time"
+
+const (
+	JobQueued    = "queued"
+	JobRendering = "rendering"
+	JobDone      = "done"
+	JobFailed    = "failed"
+	JobCanceled  = "canceled"
+)
+
+// Job is the state of an asynchronous render. Error tells why a failed job
+// failed and Filename names its pdf; finished jobs are forgotten after
+// ExpiresAt.
+type Job struct {
+	ID         string
+	Status     string
+	Filename   string `json:",omitempty"`
+	Error      string `json:",omitempty"`
+	CreatedAt  time.Time
+	StartedAt  *time.Time `json:",omitempty"`
+	FinishedAt *time.Time `json:",omitempty"`
+	ExpiresAt  *time.Time `json:",omitempty"`
+}
@@ -0,0 +1,59 @@
+package dtos
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Length is a length in inches. In JSON it is a number of inches or a
+// string holding a number and its unit, such as "10mm", "1.5cm", "0.5in",
+// "96px" or "12pt".
+type Length float64
+
+// lengthUnits are the inches in a unit.
+var lengthUnits = map[string]float64{
+	"mm": 1 / 25.4,
+	"cm": 1 / 2.54,
+	"in": 1,
+	"px": 1.0 / 96,
+	"pt": 1.0 / 72,
+}
+
+// ParseLength reads a number followed by its unit, a number alone being
+// in inches.
+func ParseLength(s string) (Length, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	factor := 1.0
+	if len(text) > 2 {
+		if f, ok := lengthUnits[text[len(text)-2:]]; ok {
+			factor = f
+			text = strings.TrimSpace(text[:len(text)-2])
+		}
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("invalid length %q, expected a number of mm, cm, in, px or pt", s)
+	}
+	return Length(value * factor), nil
+}
+
+func (l *Length) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		length, err := ParseLength(s)
+		if err != nil {
+			return err
+		}
+		*l = length
+		return nil
+	}
+	var inches float64
+	if err := json.Unmarshal(data, &inches); err != nil {
+		return fmt.Errorf("invalid length %s, expected a number of inches or a string such as \"10mm\"", data)
+	}
+	*l = Length(inches)
+	return nil
+}
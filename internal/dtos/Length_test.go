@@ -0,0 +1,48 @@
+package dtos_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLength(t *testing.T) {
+	t.Parallel()
+
+	for input, expected := range map[string]float64{
+		`1.5`:       1.5,
+		`"0.5in"`:   0.5,
+		`"25.4mm"`:  1,
+		`"1.5cm"`:   1.5 / 2.54,
+		`"96px"`:    1,
+		`"36pt"`:    0.5,
+		`" 10 MM "`: 10 / 25.4,
+		`"2"`:       2,
+	} {
+		t.Run(input, func(t *testing.T) {
+			var length dtos.Length
+
+			assert.NoError(t, json.Unmarshal([]byte(input), &length))
+			assert.InDelta(t, expected, float64(length), 1e-9)
+		})
+	}
+
+	for _, input := range []string{`"10furlongs"`, `"mm"`, `"NaN"`, `true`, `""`} {
+		t.Run(input, func(t *testing.T) {
+			var length dtos.Length
+
+			assert.Error(t, json.Unmarshal([]byte(input), &length))
+		})
+	}
+
+	t.Run("PrintOptions", func(t *testing.T) {
+		var options dtos.PrintOptions
+
+		assert.NoError(t, json.Unmarshal([]byte(`{"MarginTop": "10mm", "WithScale": 0.8}`), &options))
+		assert.InDelta(t, 10/25.4, float64(*options.MarginTop), 1e-9)
+		assert.Equal(t, 0.8, *options.WithScale)
+		assert.Nil(t, options.MarginBottom)
+	})
+}
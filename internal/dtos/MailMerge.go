@@ -0,0 +1,30 @@
+package dtos
+
+const (
+	MailMergePdf = "pdf"
+	MailMergeZip = "zip"
+)
+
+// MailMergeRequest renders its document once per row of a CSV or XLSX
+// file, the first row naming the columns. The values of a row are the Data
+// of its document, filling the {{column}} placeholders of the content, HTML
+// escaped, or given to its template. The documents are merged into a pdf,
+// or archived in a ZIP with a manifest, each named by FilenamePattern, its
+// {{column}} placeholders filled with the row values.
+type MailMergeRequest struct {
+	HtmlRequest
+	Output          string `binding:"omitempty,oneof=pdf zip" default:"pdf"`
+	FilenamePattern string
+	// Sheet is the sheet of an XLSX file, the first one by default.
+	Sheet string
+	// Delimiter separates the values of a CSV file, the comma, semicolon or
+	// tab found on its first line by default.
+	Delimiter string `binding:"omitempty,len=1"`
+}
+
+// MailMergeResponse is the merged pdf of a mail merge, along with the
+// outcome of each row.
+type MailMergeResponse struct {
+	PdfResponse
+	Rows []BatchItemResult
+}
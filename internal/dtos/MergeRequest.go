@@ -0,0 +1,18 @@
+package dtos
+
+// MergeSection is a document of a merge, rendered with its own print
+// options, header and footer templates.
+type MergeSection struct {
+	HtmlRequest
+	// Title is the bookmark of the section, "Section <n>" by default.
+	Title string
+}
+
+// MergeRequest lists the sections of a merged pdf, in order. The page
+// numbers of their header and footer templates run through the whole
+// document.
+type MergeRequest struct {
+	Sections []MergeSection `binding:"required,min=1,dive"`
+	// Filename names the pdf when it is returned as a binary attachment.
+	Filename string
+}
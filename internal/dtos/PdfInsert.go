@@ -0,0 +1,20 @@
+package dtos
+
+const (
+	InsertBefore = "before"
+	InsertAfter  = "after"
+)
+
+// PdfInsert is an existing pdf inserted into the rendered one, before or
+// after the given page of it, or of the whole document when Page is
+// omitted. It is a pdf stored on the server or an uploaded one.
+type PdfInsert struct {
+	// Name refers to <name>.pdf in the inserts directory of the server.
+	Name string `binding:"required_without=File,excluded_with=File"`
+	// File refers to an uploaded pdf by its path among the assets.
+	File     string
+	Position string `binding:"omitempty,oneof=before after" default:"after"`
+	Page     int    `binding:"omitempty,min=1"`
+	// Content is the pdf the insert refers to, once loaded.
+	Content []byte `json:"-" swaggerignore:"true"`
+}
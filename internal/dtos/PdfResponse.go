@@ -1,5 +1,7 @@
 package dtos
 
 type PdfResponse struct {
-	Content []byte
+	Content         []byte
+	Scripts         []ScriptResult   `json:",omitempty"`
+	BlockedRequests []BlockedRequest `json:",omitempty"`
 }
@@ -0,0 +1,31 @@
+package dtos
+
+// PrintOptions are the page settings of a pdf. The ones left out take the
+// value of the template of the request, then their default, the setting
+// of the browser: a Letter page without margins, header nor footer, its
+// background printed. The lengths are numbers of inches or strings with
+// their unit, such as "10mm".
+type PrintOptions struct {
+	// PaperFormat names the paper size: A3, A4, A5, Letter, Legal,
+	// Thermal58 or Thermal80, the thermal receipt rolls of 58 and 80mm,
+	// which have no page height and print with AutoHeight. It excludes
+	// PaperWidth and PaperHeight.
+	PaperFormat         string  `example:"A4"`
+	PrintBackground     *bool   `default:"true"`
+	PreferCSSPageSize   *bool   `default:"false"`
+	DisplayHeaderFooter *bool   `default:"false"`
+	Landscape           *bool   `default:"false"`
+	MarginTop           *Length `default:"0" swaggertype:"string" example:"10mm"`
+	MarginBottom        *Length `default:"0" swaggertype:"string" example:"10mm"`
+	MarginRight         *Length `default:"0" swaggertype:"string" example:"0"`
+	MarginLeft          *Length `default:"0" swaggertype:"string" example:"10mm"`
+	PaperWidth          *Length `binding:"excluded_with=PaperFormat" default:"8.5" swaggertype:"string" example:"210mm"`
+	PaperHeight         *Length `binding:"excluded_with=PaperFormat" default:"11" swaggertype:"string" example:"297mm"`
+	// WithScale scales the content, between 0.1 and 2.
+	WithScale *float64 `default:"1"`
+	// AutoHeight prints the document on a single page as high as its
+	// content laid out at the paper width, PaperHeight being ignored. A
+	// document higher than the maximum height of the server is refused.
+	// It excludes Landscape and PreferCSSPageSize.
+	AutoHeight *bool `default:"false"`
+}
@@ -0,0 +1,16 @@
+package dtos
+
+// Profile is a named set of rendering settings the operators define, one
+// JSON file per profile in the profiles directory of the server. A request
+// naming it in its Profile field takes the settings it leaves out from the
+// profile: its print options, header and footer templates, and its CSS,
+// added ahead of the request stylesheets.
+type Profile struct {
+	// Name is the name of the file of the profile, without its extension.
+	Name        string
+	Description string
+	PrintOptions
+	HeaderTemplate string
+	FooterTemplate string
+	Css            string
+}
@@ -0,0 +1,28 @@
+package dtos
+
+import "encoding/json"
+
+const (
+	ScriptBeforeLoad  = "beforeLoad"
+	ScriptAfterLoad   = "afterLoad"
+	ScriptBeforePrint = "beforePrint"
+)
+
+// Script is a piece of JavaScript run in the page at the given Stage:
+// beforeLoad runs while the document is parsed, afterLoad once it has
+// loaded and beforePrint after the WaitFor conditions are met. When
+// Source is omitted, Name refers to a script stored on the server.
+type Script struct {
+	Name   string
+	Stage  string `binding:"required,oneof=beforeLoad afterLoad beforePrint"`
+	Source string
+}
+
+// ScriptResult holds the JSON encoded value a script evaluated to, or the
+// exception it raised.
+type ScriptResult struct {
+	Name  string
+	Stage string
+	Value json.RawMessage `json:",omitempty"`
+	Error string          `json:",omitempty"`
+}
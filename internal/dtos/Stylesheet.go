@@ -0,0 +1,8 @@
+package dtos
+
+// Stylesheet is CSS added to the document head as a <style> element. Name
+// is optional and tags the element so the document can tell them apart.
+type Stylesheet struct {
+	Name    string
+	Content string `binding:"required"`
+}
@@ -0,0 +1,48 @@
+package dtos
+
+import "time"
+
+const (
+	TemplateDocument = "document"
+	TemplateLayout   = "layout"
+	TemplatePartial  = "partial"
+)
+
+// Template is a template of the registry. Its versions are immutable and
+// numbered from 1; Version is the one fetched, the latest by default.
+type Template struct {
+	ID          string
+	Kind        string
+	Description string
+	Versions    []int
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     *TemplateVersion `json:",omitempty"`
+}
+
+// TemplateVersion is the html/template content of a template version. A
+// document renders within its layout, which shows it with
+// {{template "content" .}}, and its partials are available to both under
+// their ID. The CSS, header and footer templates and the print options
+// apply to the requests rendering the document, the print options to the
+// ones the request leaves out.
+type TemplateVersion struct {
+	Version        int
+	Content        string `binding:"required"`
+	Layout         string
+	Partials       []string
+	Css            string
+	HeaderTemplate string
+	FooterTemplate string
+	Options        *PrintOptions
+	CreatedAt      time.Time
+}
+
+// TemplateRequest creates a template, or a new version of it. The Kind
+// of a template cannot change.
+type TemplateRequest struct {
+	ID          string
+	Kind        string `binding:"omitempty,oneof=document layout partial" default:"document"`
+	Description string
+	TemplateVersion
+}
@@ -0,0 +1,18 @@
+package dtos
+
+// Cookie is set in the browser before navigating to the request URL. It
+// is scoped to the URL host unless Domain is set.
+type Cookie struct {
+	Name     string `binding:"required"`
+	Value    string
+	Domain   string
+	Path     string
+	Secure   bool
+	HTTPOnly bool
+}
+
+// BasicAuth holds the credentials sent to the request URL host.
+type BasicAuth struct {
+	Username string `binding:"required"`
+	Password string
+}
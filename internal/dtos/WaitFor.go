@@ -0,0 +1,40 @@
+package dtos
+
+// WaitFor lists the conditions the page must meet before it is printed.
+// They are checked in declaration order, each one within its own Timeout
+// (milliseconds, 10 seconds when omitted).
+type WaitFor struct {
+	Selector    *WaitSelector
+	NetworkIdle *WaitNetworkIdle
+	Expression  *WaitExpression
+	Fonts       *WaitSignal
+	ReadySignal *WaitSignal
+	Delay       int
+}
+
+// WaitSelector waits for an element matching a CSS selector to be present
+// in the document, or to be visible when Visible is set.
+type WaitSelector struct {
+	Selector string `binding:"required"`
+	Visible  bool
+	Timeout  int
+}
+
+// WaitNetworkIdle waits until no request has been in flight for IdleTime
+// milliseconds.
+type WaitNetworkIdle struct {
+	IdleTime int `binding:"required"`
+	Timeout  int
+}
+
+// WaitExpression waits for a JavaScript expression to become truthy.
+type WaitExpression struct {
+	Expression string `binding:"required"`
+	Timeout    int
+}
+
+// WaitSignal waits for a signal of the page: document.fonts.ready for
+// Fonts, a call to window.html2pdfReady() for ReadySignal.
+type WaitSignal struct {
+	Timeout int
+}
@@ -0,0 +1,18 @@
+package dtos
+
+import "time"
+
+// WebhookEvent is posted to the callback URL of a job once it finished.
+// The pdf is inlined in Content when small enough, otherwise DownloadURL
+// points to the job result.
+type WebhookEvent struct {
+	ID            string
+	Status        string
+	Error         string `json:",omitempty"`
+	Size          int
+	Pages         int
+	Content       []byte `json:",omitempty"`
+	DownloadURL   string `json:",omitempty"`
+	CorrelationID string `json:",omitempty"`
+	Timestamp     time.Time
+}
@@ -2,21 +2,61 @@ package interfaces
 
 import (
 	"context"
+	"io"
 	"net/http"
-	"sync"
 
 	"github.com/chromedp/chromedp"
 	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/spreadsheet"
 )
 
 type Html2PdfServiceInterface interface {
 	HtmlToPdf(request dtos.HtmlRequest) (dtos.PdfResponse, error)
+	HtmlToPdfContext(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error)
+	HtmlToPdfStream(request dtos.HtmlRequest, w io.Writer) (dtos.PdfResponse, error)
+	HtmlToPdfMerge(ctx context.Context, request dtos.MergeRequest) (dtos.PdfResponse, error)
+	HtmlToImage(request dtos.ImageRequest) (dtos.ImageResponse, error)
 	PdfGrabber(url string, res *[]byte, request dtos.HtmlRequest) chromedp.Tasks
 	DoPdfActions(res *[]byte, request dtos.HtmlRequest, ctx context.Context) error
-	WriteHTML() http.Handler
-	DoHandler(w http.ResponseWriter, h *http.Request)
+	WriteHTML(request dtos.HtmlRequest) http.Handler
+	DoHandler(w http.ResponseWriter, h *http.Request, request dtos.HtmlRequest)
 	// DoEventLoad(ctx context.Context, wg *sync.WaitGroup, ch *chan int) error
-	DoGetFrameTree(ctx context.Context) error
-	Wait(wg *sync.WaitGroup) chromedp.ActionFunc
-	WgWait(wg *sync.WaitGroup)
+	DoGetFrameTree(ctx context.Context, request dtos.HtmlRequest) error
+}
+
+type JobServiceInterface interface {
+	Submit(request dtos.HtmlRequest, correlationID string) (dtos.Job, error)
+	Get(id string) (dtos.Job, error)
+	Result(id string) (dtos.Job, []byte, error)
+	Cancel(id string) (dtos.Job, error)
+}
+
+// TemplateStore keeps the templates of the registry. AddVersion numbers the
+// version after the latest one, updating the description of the template
+// unless it is empty.
+type TemplateStore interface {
+	List() ([]dtos.Template, error)
+	Get(id string) (dtos.Template, error)
+	GetVersion(id string, version int) (dtos.TemplateVersion, error)
+	Create(template dtos.Template, version dtos.TemplateVersion) (dtos.Template, error)
+	AddVersion(template dtos.Template, version dtos.TemplateVersion) (dtos.Template, error)
+	Delete(id string) error
+}
+
+type TemplateServiceInterface interface {
+	List() ([]dtos.Template, error)
+	Get(ref string) (dtos.Template, error)
+	Create(request dtos.TemplateRequest) (dtos.Template, error)
+	Update(id string, request dtos.TemplateRequest) (dtos.Template, error)
+	Delete(id string) error
+}
+
+type ProfileServiceInterface interface {
+	List() ([]dtos.Profile, error)
+}
+
+type BatchServiceInterface interface {
+	Check(items []dtos.HtmlRequest) error
+	Render(ctx context.Context, items []dtos.HtmlRequest, w io.Writer) ([]dtos.BatchItemResult, error)
+	MailMerge(ctx context.Context, request dtos.MailMergeRequest, rows []spreadsheet.Row, w io.Writer) ([]dtos.BatchItemResult, error)
 }
@@ -0,0 +1,27 @@
+package middlewares
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
+)
+
+// RecoveryMiddleware answers 500 to the requests whose handler panicked.
+// A handler panicking with http.ErrAbortHandler is let through, for the
+// server to reset a response it cut short instead of ending it cleanly.
+func RecoveryMiddleware(l logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			if err := recover(); err != nil {
+				if err == http.ErrAbortHandler {
+					panic(err)
+				}
+				l.Error("Panic recovered", zap.Any("panic", err), zap.Stack("stack"))
+				c.AbortWithStatus(http.StatusInternalServerError)
+			}
+		}()
+		c.Next()
+	}
+}
@@ -0,0 +1,41 @@
+package middlewares_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/middlewares"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryMiddleware(t *testing.T) {
+	t.Parallel()
+
+	r := gin.New()
+	r.Use(middlewares.RecoveryMiddleware(logger.NewFakeLogger()))
+	r.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+	r.GET("/abort", func(c *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	t.Run("Answers 500 to a panicking handler", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", "/panic", nil)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+
+	t.Run("Lets an aborted handler through", func(t *testing.T) {
+		req, _ := http.NewRequest("GET", "/abort", nil)
+
+		assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+			r.ServeHTTP(httptest.NewRecorder(), req)
+		})
+	})
+}
@@ -0,0 +1,615 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrInvalid reports data that is not a readable PDF document.
+	ErrInvalid = errors.New("invalid pdf")
+	// ErrEncrypted reports an encrypted document, which cannot be read.
+	ErrEncrypted = errors.New("encrypted pdf")
+)
+
+// maxDecodedSize bounds the size of the decoded cross-reference and object
+// streams.
+const maxDecodedSize = 64 << 20
+
+type xrefEntry struct {
+	offset     int
+	stream     int
+	index      int
+	compressed bool
+}
+
+type objectStream struct {
+	data    []byte
+	offsets []int
+}
+
+type page struct {
+	ref  Ref
+	dict Dict
+}
+
+// Document is a parsed PDF document. Its objects are read as they are
+// needed.
+type Document struct {
+	data      []byte
+	xref      map[int]xrefEntry
+	trailer   Dict
+	objects   map[int]interface{}
+	streams   map[int]*objectStream
+	resolving map[int]bool
+	pages     []page
+}
+
+// Parse reads the cross-reference and the page tree of data, rebuilding
+// the cross-reference from the objects found when it is broken.
+func Parse(data []byte) (*Document, error) {
+	if i := bytes.Index(data, []byte("%PDF-")); i < 0 || i > 1024 {
+		return nil, fmt.Errorf("%w: missing header", ErrInvalid)
+	}
+
+	d := newDocument(data)
+	err := d.readXref()
+	if err == nil {
+		err = d.readPages()
+	}
+	if err != nil && !errors.Is(err, ErrEncrypted) {
+		d = newDocument(data)
+		if err = d.reconstruct(); err == nil {
+			err = d.readPages()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(d.pages) == 0 {
+		return nil, fmt.Errorf("%w: no pages", ErrInvalid)
+	}
+	return d, nil
+}
+
+func newDocument(data []byte) *Document {
+	return &Document{
+		data:      data,
+		xref:      map[int]xrefEntry{},
+		objects:   map[int]interface{}{},
+		streams:   map[int]*objectStream{},
+		resolving: map[int]bool{},
+	}
+}
+
+// NumPages is the number of pages of the document.
+func (d *Document) NumPages() int {
+	return len(d.pages)
+}
+
+func (d *Document) readXref() error {
+	i := bytes.LastIndex(d.data, []byte("startxref"))
+	if i < 0 {
+		return fmt.Errorf("%w: missing startxref", ErrInvalid)
+	}
+	p := &parser{data: d.data, pos: i + len("startxref")}
+	offset, err := p.int()
+	if err != nil {
+		return err
+	}
+
+	seen := map[int]bool{}
+	for !seen[offset] {
+		seen[offset] = true
+		trailer, err := d.readSection(offset)
+		if err != nil {
+			return err
+		}
+		if d.trailer == nil {
+			d.trailer = trailer
+		}
+		if stm, ok := trailer["XRefStm"].(int64); ok {
+			if _, err := d.readSection(int(stm)); err != nil {
+				return err
+			}
+		}
+		prev, ok := trailer["Prev"].(int64)
+		if !ok {
+			break
+		}
+		offset = int(prev)
+	}
+	return d.checkTrailer()
+}
+
+func (d *Document) checkTrailer() error {
+	if _, ok := d.trailer["Encrypt"]; ok {
+		return ErrEncrypted
+	}
+	if _, ok := d.trailer["Root"].(Ref); !ok {
+		return fmt.Errorf("%w: missing catalog", ErrInvalid)
+	}
+	return nil
+}
+
+// readSection reads the cross-reference table or stream at offset. The
+// entries already known, from a more recent section, are kept.
+func (d *Document) readSection(offset int) (Dict, error) {
+	if offset < 0 || offset >= len(d.data) {
+		return nil, fmt.Errorf("%w: cross-reference offset out of range", ErrInvalid)
+	}
+	p := &parser{data: d.data, pos: offset, doc: d}
+	p.skipSpace()
+	if !bytes.HasPrefix(d.data[p.pos:], []byte("xref")) {
+		return d.readXrefStream(p)
+	}
+
+	p.pos += len("xref")
+	for {
+		p.skipSpace()
+		save := p.pos
+		if p.regular() == "trailer" {
+			o, err := p.object(0)
+			if err != nil {
+				return nil, err
+			}
+			trailer, ok := o.(Dict)
+			if !ok {
+				return nil, p.errorf("trailer is not a dictionary")
+			}
+			return trailer, nil
+		}
+		p.pos = save
+		start, err := p.int()
+		if err != nil {
+			return nil, err
+		}
+		count, err := p.int()
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < count; i++ {
+			offset, err := p.int()
+			if err != nil {
+				return nil, err
+			}
+			if _, err := p.int(); err != nil {
+				return nil, err
+			}
+			p.skipSpace()
+			kind := p.regular()
+			if kind != "n" && kind != "f" {
+				return nil, p.errorf("invalid cross-reference entry")
+			}
+			if _, ok := d.xref[start+i]; !ok && kind == "n" {
+				d.xref[start+i] = xrefEntry{offset: offset}
+			}
+		}
+	}
+}
+
+func (d *Document) readXrefStream(p *parser) (Dict, error) {
+	_, o, err := p.indirect()
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := o.(*Stream)
+	if !ok || stream.Dict["Type"] != Name("XRef") {
+		return nil, p.errorf("cross-reference expected")
+	}
+	data, err := decode(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	widths, _ := stream.Dict["W"].(Array)
+	if len(widths) != 3 {
+		return nil, p.errorf("invalid cross-reference stream widths")
+	}
+	var w [3]int
+	for i, v := range widths {
+		n, ok := v.(int64)
+		if !ok || n < 0 || n > 8 {
+			return nil, p.errorf("invalid cross-reference stream widths")
+		}
+		w[i] = int(n)
+	}
+	index, _ := stream.Dict["Index"].(Array)
+	if index == nil {
+		index = Array{int64(0), stream.Dict["Size"]}
+	}
+
+	field := func(width int, def int) int {
+		if width == 0 {
+			return def
+		}
+		v := 0
+		for i := 0; i < width; i++ {
+			v = v<<8 | int(data[i])
+		}
+		data = data[width:]
+		return v
+	}
+	entry := w[0] + w[1] + w[2]
+	for i := 0; i+1 < len(index); i += 2 {
+		start, _ := index[i].(int64)
+		count, _ := index[i+1].(int64)
+		for n := 0; n < int(count) && len(data) >= entry && entry > 0; n++ {
+			kind, f2, f3 := field(w[0], 1), field(w[1], 0), field(w[2], 0)
+			num := int(start) + n
+			if _, ok := d.xref[num]; ok {
+				continue
+			}
+			switch kind {
+			case 1:
+				d.xref[num] = xrefEntry{offset: f2}
+			case 2:
+				d.xref[num] = xrefEntry{stream: f2, index: f3, compressed: true}
+			}
+		}
+	}
+	return stream.Dict, nil
+}
+
+var objectPattern = regexp.MustCompile(`(\d+)[\x00\t\n\f\r ]+(\d+)[\x00\t\n\f\r ]+obj\b`)
+
+// reconstruct rebuilds the cross-reference out of the object definitions
+// found in the data, the later definitions replacing the earlier ones.
+func (d *Document) reconstruct() error {
+	for _, m := range objectPattern.FindAllSubmatchIndex(d.data, -1) {
+		num, err := strconv.Atoi(string(d.data[m[2]:m[3]]))
+		if err != nil {
+			continue
+		}
+		d.xref[num] = xrefEntry{offset: m[0]}
+	}
+
+	nums := make([]int, 0, len(d.xref))
+	for num := range d.xref {
+		nums = append(nums, num)
+	}
+	for _, num := range nums {
+		o, err := d.object(Ref{Num: num})
+		if err != nil {
+			continue
+		}
+		stream, ok := o.(*Stream)
+		if !ok {
+			continue
+		}
+		switch stream.Dict["Type"] {
+		case Name("ObjStm"):
+			if s, err := d.objectStream(num); err == nil {
+				d.addCompressed(num, s)
+			}
+		case Name("XRef"):
+			if _, ok := stream.Dict["Root"]; ok {
+				d.trailer = stream.Dict
+			}
+		}
+	}
+
+	if i := bytes.LastIndex(d.data, []byte("trailer")); i >= 0 {
+		p := &parser{data: d.data, pos: i + len("trailer"), doc: d}
+		if o, err := p.object(0); err == nil {
+			if trailer, ok := o.(Dict); ok {
+				d.trailer = trailer
+			}
+		}
+	}
+	if d.trailer == nil {
+		d.trailer = Dict{}
+	}
+	if _, ok := d.trailer["Root"].(Ref); !ok {
+		for num := range d.xref {
+			o, _ := d.object(Ref{Num: num})
+			if dict, ok := o.(Dict); ok && dict["Type"] == Name("Catalog") {
+				d.trailer["Root"] = Ref{Num: num}
+			}
+		}
+	}
+	return d.checkTrailer()
+}
+
+// addCompressed registers the objects of an object stream that are not
+// defined elsewhere.
+func (d *Document) addCompressed(num int, s *objectStream) {
+	header := &parser{data: s.data}
+	for i := range s.offsets {
+		obj, err := header.int()
+		if err != nil {
+			return
+		}
+		if _, err := header.int(); err != nil {
+			return
+		}
+		if _, ok := d.xref[obj]; !ok {
+			d.xref[obj] = xrefEntry{stream: num, index: i, compressed: true}
+		}
+	}
+}
+
+// object returns the object ref refers to, nil when it is not defined.
+func (d *Document) object(ref Ref) (interface{}, error) {
+	if o, ok := d.objects[ref.Num]; ok {
+		return o, nil
+	}
+	e, ok := d.xref[ref.Num]
+	if !ok {
+		return nil, nil
+	}
+	if d.resolving[ref.Num] {
+		return nil, fmt.Errorf("%w: object %d refers to itself", ErrInvalid, ref.Num)
+	}
+	d.resolving[ref.Num] = true
+	defer delete(d.resolving, ref.Num)
+
+	var o interface{}
+	if e.compressed {
+		s, err := d.objectStream(e.stream)
+		if err != nil {
+			return nil, err
+		}
+		if e.index >= len(s.offsets) {
+			return nil, fmt.Errorf("%w: object %d out of its object stream", ErrInvalid, ref.Num)
+		}
+		p := &parser{data: s.data, pos: s.offsets[e.index], doc: d}
+		if o, err = p.object(0); err != nil {
+			return nil, err
+		}
+	} else {
+		if e.offset < 0 || e.offset >= len(d.data) {
+			return nil, fmt.Errorf("%w: object %d out of range", ErrInvalid, ref.Num)
+		}
+		p := &parser{data: d.data, pos: e.offset, doc: d}
+		def, obj, err := p.indirect()
+		if err != nil {
+			return nil, err
+		}
+		if def.Num != ref.Num {
+			return nil, fmt.Errorf("%w: object %d not found at its offset", ErrInvalid, ref.Num)
+		}
+		o = obj
+	}
+	d.objects[ref.Num] = o
+	return o, nil
+}
+
+func (d *Document) objectStream(num int) (*objectStream, error) {
+	if s, ok := d.streams[num]; ok {
+		return s, nil
+	}
+	if e, ok := d.xref[num]; ok && e.compressed {
+		return nil, fmt.Errorf("%w: object stream %d is compressed", ErrInvalid, num)
+	}
+	o, err := d.object(Ref{Num: num})
+	if err != nil {
+		return nil, err
+	}
+	stream, ok := o.(*Stream)
+	if !ok {
+		return nil, fmt.Errorf("%w: object %d is not an object stream", ErrInvalid, num)
+	}
+	data, err := decode(stream)
+	if err != nil {
+		return nil, err
+	}
+	n, _ := stream.Dict["N"].(int64)
+	first, _ := stream.Dict["First"].(int64)
+	if n < 0 || first < 0 || int(first) > len(data) {
+		return nil, fmt.Errorf("%w: invalid object stream %d", ErrInvalid, num)
+	}
+
+	s := &objectStream{data: data}
+	header := &parser{data: data[:first]}
+	for i := 0; i < int(n); i++ {
+		if _, err := header.int(); err != nil {
+			return nil, err
+		}
+		offset, err := header.int()
+		if err != nil {
+			return nil, err
+		}
+		s.offsets = append(s.offsets, int(first)+offset)
+	}
+	d.streams[num] = s
+	return s, nil
+}
+
+func (d *Document) resolve(o interface{}) (interface{}, error) {
+	if ref, ok := o.(Ref); ok {
+		return d.object(ref)
+	}
+	return o, nil
+}
+
+// inheritable are the page attributes a page takes from its ancestors.
+var inheritable = []Name{"Resources", "MediaBox", "CropBox", "Rotate"}
+
+func (d *Document) readPages() error {
+	o, err := d.resolve(d.trailer["Root"])
+	if err != nil {
+		return err
+	}
+	catalog, ok := o.(Dict)
+	if !ok {
+		return fmt.Errorf("%w: missing catalog", ErrInvalid)
+	}
+	d.pages = nil
+	return d.walk(catalog["Pages"], Dict{}, map[int]bool{}, 0)
+}
+
+func (d *Document) walk(node interface{}, inherited Dict, seen map[int]bool, depth int) error {
+	ref, _ := node.(Ref)
+	if ref.Num > 0 {
+		if seen[ref.Num] {
+			return fmt.Errorf("%w: page tree loops", ErrInvalid)
+		}
+		seen[ref.Num] = true
+	}
+	if depth > maxDepth {
+		return fmt.Errorf("%w: page tree too deep", ErrInvalid)
+	}
+	o, err := d.resolve(node)
+	if err != nil {
+		return err
+	}
+	dict, ok := o.(Dict)
+	if !ok {
+		return fmt.Errorf("%w: invalid page tree node", ErrInvalid)
+	}
+
+	attrs := Dict{}
+	for k, v := range inherited {
+		attrs[k] = v
+	}
+	for _, k := range inheritable {
+		if v, ok := dict[k]; ok {
+			attrs[k] = v
+		}
+	}
+
+	o, err = d.resolve(dict["Kids"])
+	if err != nil {
+		return err
+	}
+	kids, isNode := o.(Array)
+	if dict["Type"] == Name("Page") || (dict["Type"] == nil && !isNode) {
+		page := page{ref: ref, dict: Dict{}}
+		for k, v := range dict {
+			page.dict[k] = v
+		}
+		for k, v := range attrs {
+			page.dict[k] = v
+		}
+		d.pages = append(d.pages, page)
+		return nil
+	}
+	for _, kid := range kids {
+		if err := d.walk(kid, attrs, seen, depth+1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decode applies the filters of a stream, only FlateDecode being supported.
+func decode(stream *Stream) ([]byte, error) {
+	filters := Array{}
+	switch f := stream.Dict["Filter"].(type) {
+	case Name:
+		filters = Array{f}
+	case Array:
+		filters = f
+	}
+	params := Array{}
+	switch p := stream.Dict["DecodeParms"].(type) {
+	case Dict:
+		params = Array{p}
+	case Array:
+		params = p
+	}
+
+	data := stream.Data
+	for i, f := range filters {
+		if f != Name("FlateDecode") {
+			return nil, fmt.Errorf("%w: unsupported filter %v", ErrInvalid, f)
+		}
+		r, err := zlib.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, err)
+		}
+		decoded, err := io.ReadAll(io.LimitReader(r, maxDecodedSize+1))
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, err)
+		}
+		if len(decoded) > maxDecodedSize {
+			return nil, fmt.Errorf("%w: stream too large", ErrInvalid)
+		}
+		data = decoded
+		if i < len(params) {
+			if p, ok := params[i].(Dict); ok {
+				if data, err = unpredict(data, p); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return data, nil
+}
+
+// unpredict reverses the PNG predictors of the decode parameters p.
+func unpredict(data []byte, p Dict) ([]byte, error) {
+	predictor, _ := p["Predictor"].(int64)
+	if predictor < 10 {
+		if predictor > 1 {
+			return nil, fmt.Errorf("%w: unsupported predictor %d", ErrInvalid, predictor)
+		}
+		return data, nil
+	}
+	colors, bits, columns := int64(1), int64(8), int64(1)
+	if v, ok := p["Colors"].(int64); ok {
+		colors = v
+	}
+	if v, ok := p["BitsPerComponent"].(int64); ok {
+		bits = v
+	}
+	if v, ok := p["Columns"].(int64); ok {
+		columns = v
+	}
+	if colors < 1 || bits < 1 || columns < 1 || colors*bits*columns > 1<<20 {
+		return nil, fmt.Errorf("%w: invalid predictor parameters", ErrInvalid)
+	}
+	bpp := int((colors*bits + 7) / 8)
+	row := int((colors*bits*columns + 7) / 8)
+
+	var out []byte
+	prev := make([]byte, row)
+	for len(data) >= row+1 {
+		kind, cur := data[0], append([]byte(nil), data[1:row+1]...)
+		data = data[row+1:]
+		for i := range cur {
+			var left, upLeft byte
+			if i >= bpp {
+				left, upLeft = cur[i-bpp], prev[i-bpp]
+			}
+			switch kind {
+			case 1:
+				cur[i] += left
+			case 2:
+				cur[i] += prev[i]
+			case 3:
+				cur[i] += byte((int(left) + int(prev[i])) / 2)
+			case 4:
+				cur[i] += paeth(left, prev[i], upLeft)
+			}
+		}
+		out = append(out, cur...)
+		prev = cur
+	}
+	return out, nil
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	if pa <= pb && pa <= pc {
+		return a
+	}
+	if pb <= pc {
+		return b
+	}
+	return c
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,149 @@
+package pdf_test
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+// buildPdf writes the objects, numbered from 1, with a cross-reference
+// table and the trailer entries.
+func buildPdf(trailer string, objects ...string) []byte {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d %s >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, trailer, xref)
+	return b.Bytes()
+}
+
+// samplePdf is a document of pages pages, sharing the resources and the
+// media box of their parent.
+func samplePdf(pages int) []byte {
+	kids := make([]string, pages)
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >>",
+		"",
+		"<< /Font << /F1 << /Type /Font /Subtype /Type1 /BaseFont /Helvetica >> >> >>",
+	}
+	for i := range kids {
+		content := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (Page %d) Tj ET", i+1)
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Contents %d 0 R /AA << /O << /S /JavaScript /JS (x) >> >> >>", len(objects)+2),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(content), content))
+		kids[i] = fmt.Sprintf("%d 0 R", len(objects)-1)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /Resources 3 0 R /MediaBox [0 0 612 792] >>", strings.Join(kids, " "), pages)
+	return buildPdf("/Root 1 0 R", objects...)
+}
+
+func deflate(data string) string {
+	var b bytes.Buffer
+	w := zlib.NewWriter(&b)
+	w.Write([]byte(data))
+	w.Close()
+	return b.String()
+}
+
+// compressedPdf keeps its page tree in an object stream indexed by a
+// cross-reference stream with a PNG predictor.
+func compressedPdf() []byte {
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"<< /Type /Pages /Kids [3 0 R] /Count 1 >>",
+		"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 100 100] >>",
+	}
+	var header, body bytes.Buffer
+	for i, o := range objects {
+		fmt.Fprintf(&header, "%d %d ", i+1, body.Len())
+		body.WriteString(o + "\n")
+	}
+	stm := deflate(header.String() + body.String())
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.5\n")
+	stmOffset := b.Len()
+	fmt.Fprintf(&b, "4 0 obj\n<< /Type /ObjStm /N 3 /First %d /Filter /FlateDecode /Length %d >>\nstream\n%s\nendstream\nendobj\n", header.Len(), len(stm), stm)
+	xrefOffset := b.Len()
+
+	// rows of type, offset (2 bytes), index, each with the Up predictor
+	rows := [][]byte{{0, 0, 0, 0}, {2, 0, 4, 0}, {2, 0, 4, 1}, {2, 0, 4, 2}, {1, byte(stmOffset >> 8), byte(stmOffset), 0}, {1, byte(xrefOffset >> 8), byte(xrefOffset), 0}}
+	var raw []byte
+	prev := make([]byte, 4)
+	for _, row := range rows {
+		raw = append(raw, 2)
+		for i := range row {
+			raw = append(raw, row[i]-prev[i])
+		}
+		prev = row
+	}
+	xref := deflate(string(raw))
+	fmt.Fprintf(&b, "5 0 obj\n<< /Type /XRef /Size 6 /W [1 2 1] /Root 1 0 R /Filter /FlateDecode /DecodeParms << /Predictor 12 /Columns 4 >> /Length %d >>\nstream\n%s\nendstream\nendobj\n", len(xref), xref)
+	fmt.Fprintf(&b, "startxref\n%d\n%%%%EOF\n", xrefOffset)
+	return b.Bytes()
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TestParsePages", func(t *testing.T) {
+		doc, err := pdf.Parse(samplePdf(3))
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, doc.NumPages())
+	})
+
+	t.Run("TestParseCompressed", func(t *testing.T) {
+		doc, err := pdf.Parse(compressedPdf())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, doc.NumPages())
+	})
+
+	t.Run("TestParseBrokenXref", func(t *testing.T) {
+		data := samplePdf(2)
+		data = bytes.Replace(data, []byte("startxref\n"), []byte("startxref\n1"), 1)
+
+		doc, err := pdf.Parse(data)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, doc.NumPages())
+	})
+
+	t.Run("TestParseEncrypted", func(t *testing.T) {
+		data := buildPdf("/Root 1 0 R /Encrypt 3 0 R",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [] /Count 0 >>",
+			"<< /Filter /Standard >>")
+
+		_, err := pdf.Parse(data)
+
+		assert.ErrorIs(t, err, pdf.ErrEncrypted)
+	})
+
+	t.Run("TestParseInvalid", func(t *testing.T) {
+		for name, data := range map[string][]byte{
+			"NotPdf":  []byte("<html></html>"),
+			"NoPages": buildPdf("/Root 1 0 R", "<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [] /Count 0 >>"),
+			"Loop":    buildPdf("/Root 1 0 R", "<< /Type /Catalog /Pages 2 0 R >>", "<< /Type /Pages /Kids [2 0 R] /Count 1 >>"),
+			"Garbage": []byte("%PDF-1.4\n1 0 obj\n<< /Type /Catalog /Pages [ >>\nendobj\n"),
+		} {
+			_, err := pdf.Parse(data)
+
+			assert.ErrorIs(t, err, pdf.ErrInvalid, name)
+		}
+	})
+}
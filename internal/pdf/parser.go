@@ -0,0 +1,360 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+)
+
+// maxDepth bounds the nesting of arrays and dictionaries.
+const maxDepth = 100
+
+// parser reads objects out of data from pos. The stream lengths that are
+// indirect objects are resolved through doc.
+type parser struct {
+	data []byte
+	pos  int
+	doc  *Document
+}
+
+func isSpace(c byte) bool {
+	switch c {
+	case 0, '\t', '\n', '\f', '\r', ' ':
+		return true
+	}
+	return false
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalid, fmt.Sprintf(format, args...), p.pos)
+}
+
+// skipSpace skips the white space and the comments.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		if !isSpace(c) {
+			return
+		}
+		p.pos++
+	}
+}
+
+// regular reads a run of regular characters: a keyword or a number.
+func (p *parser) regular() string {
+	start := p.pos
+	for p.pos < len(p.data) && !isSpace(p.data[p.pos]) && !isDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	return string(p.data[start:p.pos])
+}
+
+// int reads a non negative integer.
+func (p *parser) int() (int, error) {
+	p.skipSpace()
+	tok := p.regular()
+	n, err := strconv.Atoi(tok)
+	if err != nil || n < 0 {
+		return 0, p.errorf("integer expected, got %q", tok)
+	}
+	return n, nil
+}
+
+// indirect reads an indirect object definition.
+func (p *parser) indirect() (Ref, interface{}, error) {
+	num, err := p.int()
+	if err != nil {
+		return Ref{}, nil, err
+	}
+	gen, err := p.int()
+	if err != nil {
+		return Ref{}, nil, err
+	}
+	p.skipSpace()
+	if tok := p.regular(); tok != "obj" {
+		return Ref{}, nil, p.errorf("obj expected, got %q", tok)
+	}
+	o, err := p.object(0)
+	return Ref{Num: num, Gen: gen}, o, err
+}
+
+func (p *parser) object(depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, p.errorf("objects nested too deep")
+	}
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, p.errorf("unexpected end of data")
+	}
+	switch p.data[p.pos] {
+	case '/':
+		return p.name(), nil
+	case '(':
+		return p.literal()
+	case '<':
+		if p.pos+1 < len(p.data) && p.data[p.pos+1] == '<' {
+			p.pos += 2
+			return p.dict(depth)
+		}
+		return p.hex()
+	case '[':
+		p.pos++
+		arr := Array{}
+		for {
+			p.skipSpace()
+			if p.pos < len(p.data) && p.data[p.pos] == ']' {
+				p.pos++
+				return arr, nil
+			}
+			o, err := p.object(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, o)
+		}
+	}
+
+	tok := p.regular()
+	switch tok {
+	case "":
+		return nil, p.errorf("unexpected %q", p.data[p.pos])
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if n, err := strconv.ParseInt(tok, 10, 64); err == nil {
+		save := p.pos
+		if ref, ok := p.ref(n); ok {
+			return ref, nil
+		}
+		p.pos = save
+		return n, nil
+	}
+	if isReal(tok) {
+		if f, err := strconv.ParseFloat(tok, 64); err == nil {
+			return f, nil
+		}
+	}
+	return nil, p.errorf("unexpected %q", tok)
+}
+
+func isReal(tok string) bool {
+	for i := 0; i < len(tok); i++ {
+		if c := tok[i]; (c < '0' || c > '9') && c != '.' && c != '-' && c != '+' {
+			return false
+		}
+	}
+	return true
+}
+
+// ref reads the rest of a reference whose object number is num.
+func (p *parser) ref(num int64) (Ref, bool) {
+	p.skipSpace()
+	gen, err := strconv.Atoi(p.regular())
+	if err != nil || gen < 0 || num < 0 || num > 1<<31 {
+		return Ref{}, false
+	}
+	p.skipSpace()
+	if p.regular() != "R" {
+		return Ref{}, false
+	}
+	return Ref{Num: int(num), Gen: gen}, true
+}
+
+func (p *parser) name() Name {
+	p.pos++
+	raw := p.regular()
+	var b []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if c, err := strconv.ParseUint(raw[i+1:i+3], 16, 8); err == nil {
+				b = append(b, byte(c))
+				i += 2
+				continue
+			}
+		}
+		b = append(b, raw[i])
+	}
+	return Name(b)
+}
+
+func (p *parser) literal() (String, error) {
+	p.pos++
+	var b []byte
+	nesting := 0
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			nesting++
+		case ')':
+			if nesting == 0 {
+				return String(b), nil
+			}
+			nesting--
+		case '\\':
+			if p.pos >= len(p.data) {
+				break
+			}
+			c = p.data[p.pos]
+			p.pos++
+			switch c {
+			case 'n':
+				c = '\n'
+			case 'r':
+				c = '\r'
+			case 't':
+				c = '\t'
+			case 'b':
+				c = '\b'
+			case 'f':
+				c = '\f'
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+				continue
+			case '\n':
+				continue
+			default:
+				if c >= '0' && c <= '7' {
+					v := int(c - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					c = byte(v)
+				}
+			}
+		}
+		b = append(b, c)
+	}
+	return "", p.errorf("unterminated string")
+}
+
+func (p *parser) hex() (String, error) {
+	p.pos++
+	var b []byte
+	half := -1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		if c == '>' {
+			if half >= 0 {
+				b = append(b, byte(half<<4))
+			}
+			return String(b), nil
+		}
+		if isSpace(c) {
+			continue
+		}
+		v, err := strconv.ParseUint(string(c), 16, 8)
+		if err != nil {
+			return "", p.errorf("invalid hex string")
+		}
+		if half < 0 {
+			half = int(v)
+			continue
+		}
+		b = append(b, byte(half<<4|int(v)))
+		half = -1
+	}
+	return "", p.errorf("unterminated hex string")
+}
+
+// dict reads a dictionary, and the stream following it if there is one.
+// The null entries are dropped, as if absent.
+func (p *parser) dict(depth int) (interface{}, error) {
+	dict := Dict{}
+	for {
+		p.skipSpace()
+		if bytes.HasPrefix(p.data[p.pos:], []byte(">>")) {
+			p.pos += 2
+			break
+		}
+		if p.pos >= len(p.data) || p.data[p.pos] != '/' {
+			return nil, p.errorf("name expected in dictionary")
+		}
+		key := p.name()
+		value, err := p.object(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if value != nil {
+			dict[key] = value
+		}
+	}
+
+	save := p.pos
+	p.skipSpace()
+	if p.regular() != "stream" {
+		p.pos = save
+		return dict, nil
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\r' {
+		p.pos++
+	}
+	if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+		p.pos++
+	}
+	return p.stream(dict)
+}
+
+var endstream = []byte("endstream")
+
+// stream reads the data of a stream, relying on its Length when it is
+// right and looking for its end otherwise.
+func (p *parser) stream(dict Dict) (*Stream, error) {
+	start := p.pos
+	length := -1
+	if n, ok := p.resolveLength(dict["Length"]); ok {
+		length = n
+	}
+	if length >= 0 && start+length <= len(p.data) {
+		end := &parser{data: p.data, pos: start + length}
+		end.skipSpace()
+		if bytes.HasPrefix(p.data[end.pos:], endstream) {
+			p.pos = end.pos + len(endstream)
+			return &Stream{Dict: dict, Data: p.data[start : start+length]}, nil
+		}
+	}
+
+	i := bytes.Index(p.data[start:], endstream)
+	if i < 0 {
+		return nil, p.errorf("unterminated stream")
+	}
+	data := p.data[start : start+i]
+	data = bytes.TrimSuffix(data, []byte("\n"))
+	data = bytes.TrimSuffix(data, []byte("\r"))
+	p.pos = start + i + len(endstream)
+	return &Stream{Dict: dict, Data: data}, nil
+}
+
+func (p *parser) resolveLength(o interface{}) (int, bool) {
+	if ref, ok := o.(Ref); ok && p.doc != nil {
+		var err error
+		if o, err = p.doc.object(ref); err != nil {
+			return 0, false
+		}
+	}
+	n, ok := o.(int64)
+	return int(n), ok && n >= 0 && n <= int64(len(p.data))
+}
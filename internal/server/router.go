@@ -1,16 +1,44 @@
 package server
 
 import (
+	"fmt"
+	"os"
+
 	"github.com/kolzxx/html2pdf/internal/controllers"
+	"github.com/kolzxx/html2pdf/internal/services"
 )
 
 func (s server) RegisterRoutes() {
+	ts, err := services.NewTemplateService(s.Logger)
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("Template registry error! %s", err.Error()))
+		os.Exit(1)
+	}
+
 	hc := controllers.NewHealthControler(s.Logger)
 	pc := controllers.NewHtml2PdfController(s.Logger)
+	jc := controllers.NewJobsController(s.Logger, pc.JobService())
+	tc := controllers.NewTemplatesController(s.Logger, ts)
+	fc := controllers.NewProfilesController(s.Logger, services.NewProfileService(s.Logger))
 
 	s.router.GET("/healthcheck", hc.HandleGetHealthCheck)
 	v1 := s.router.Group("/v1")
 	{
 		v1.POST("/html2pdf", pc.HandleHttp2Pdf)
+		v1.POST("/html2pdf/batch", pc.HandleHtml2PdfBatch)
+		v1.POST("/html2pdf/merge", pc.HandleHtml2PdfMerge)
+		v1.POST("/html2pdf/mailmerge", pc.HandleHtml2PdfMailMerge)
+		v1.POST("/html2image", pc.HandleHtml2Image)
+		v1.POST("/jobs", jc.HandleCreateJob)
+		v1.GET("/jobs/:id", jc.HandleGetJob)
+		v1.GET("/jobs/:id/result", jc.HandleGetJobResult)
+		v1.DELETE("/jobs/:id", jc.HandleDeleteJob)
+		v1.GET("/templates", tc.HandleListTemplates)
+		v1.POST("/templates", tc.HandleCreateTemplate)
+		v1.GET("/templates/:id", tc.HandleGetTemplate)
+		v1.GET("/templates/:id/versions/:version", tc.HandleGetTemplateVersion)
+		v1.PUT("/templates/:id", tc.HandleUpdateTemplate)
+		v1.DELETE("/templates/:id", tc.HandleDeleteTemplate)
+		v1.GET("/profiles", fc.HandleListProfiles)
 	}
 }
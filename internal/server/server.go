@@ -68,7 +68,7 @@ func (s server) SetupServer() {
 func (s server) SetupMiddlewares() {
 	s.router.Use(middlewares.CorrelationIdMiddleware(s.Logger))
 	s.router.Use(middlewares.ECSMiddleware(s.Logger))
-	s.router.Use(gin.Recovery())
+	s.router.Use(middlewares.RecoveryMiddleware(s.Logger))
 }
 
 func (s server) SetupSwagger() {
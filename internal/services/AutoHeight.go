@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// cssPixelsPerInch is the resolution of the CSS pixels.
+const cssPixelsPerInch = 96
+
+// autoHeightSlack is added to the measured height, for the rounding of the
+// browser not to push the last line onto a second page.
+const autoHeightSlack = dtos.Length(2.0 / cssPixelsPerInch)
+
+// contentHeightScript returns the height of the laid out document, in CSS
+// pixels, the viewport being one pixel high.
+const contentHeightScript = `(() => {
+	const root = document.documentElement;
+	return Math.max(root.scrollHeight, root.getBoundingClientRect().height,
+		document.body ? document.body.scrollHeight : 0);
+})()`
+
+// fitPageHeight sets the paper height of an auto height request to the
+// height of its content laid out at the width it is printed at: the paper
+// width within the margins, at the scale of the request. Content higher
+// than the maximum height of the server fails with ErrInvalidRequest.
+func (r *html2PdfService) fitPageHeight(ctx context.Context, request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	applyPrintOptions(&request, &printDefaults)
+	if !*request.AutoHeight {
+		return request, nil
+	}
+	scale := *request.WithScale
+	width := float64(*request.PaperWidth-*request.MarginLeft-*request.MarginRight) * cssPixelsPerInch / scale
+	if err := emulation.SetDeviceMetricsOverride(int64(math.Max(1, math.Round(width))), 1, 1, false).Do(ctx); err != nil {
+		return request, err
+	}
+	defer emulation.ClearDeviceMetricsOverride().Do(ctx)
+	if err := emulation.SetEmulatedMedia().WithMedia("print").Do(ctx); err != nil {
+		return request, err
+	}
+	defer emulation.SetEmulatedMedia().Do(ctx)
+
+	var pixels float64
+	if err := chromedp.Evaluate(contentHeightScript, &pixels).Do(ctx); err != nil {
+		return request, err
+	}
+	height := pageHeight(request, pixels)
+	if r.maxAutoHeight > 0 && float64(height) > r.maxAutoHeight {
+		return request, fmt.Errorf("%w: AutoHeight page %.2fin high, above the maximum of %gin", ErrInvalidRequest, float64(height), r.maxAutoHeight)
+	}
+	request.PaperHeight = &height
+	return request, nil
+}
+
+// pageHeight is the paper height fitting content pixels high at the scale
+// and within the margins of the request.
+func pageHeight(request dtos.HtmlRequest, pixels float64) dtos.Length {
+	content := dtos.Length(math.Ceil(pixels) * *request.WithScale / cssPixelsPerInch)
+	return content + *request.MarginTop + *request.MarginBottom + autoHeightSlack
+}
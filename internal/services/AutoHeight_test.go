@@ -0,0 +1,79 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAutoHeight(t *testing.T) {
+	t.Parallel()
+
+	yes := true
+	auto := dtos.PrintOptions{PaperFormat: "Thermal80", AutoHeight: &yes}
+
+	t.Run("TestPageHeight", func(t *testing.T) {
+		scale, top, bottom := 0.5, dtos.Length(0.25), dtos.Length(0.5)
+		request := dtos.HtmlRequest{PrintOptions: dtos.PrintOptions{WithScale: &scale, MarginTop: &top, MarginBottom: &bottom}}
+
+		height := services.PageHeight(request, 959.2)
+
+		assert.InDelta(t, 960*0.5/96+0.75+2.0/96, float64(height), 1e-9)
+	})
+
+	t.Run("TestPrintParamsFirstPage", func(t *testing.T) {
+		request, err := services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: auto})
+		assert.NoError(t, err)
+
+		assert.Equal(t, "1", services.PrintParams(request).PageRanges)
+		assert.Empty(t, services.PrintParams(dtos.HtmlRequest{}).PageRanges)
+	})
+
+	t.Run("TestAutoHeightOptions", func(t *testing.T) {
+		margin := dtos.Length(20)
+		options := auto
+		options.MarginTop, options.MarginBottom = &margin, &margin
+		_, err := services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: options})
+		assert.NoError(t, err)
+
+		options = auto
+		options.Landscape = &yes
+		_, err = services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: options})
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestAutoHeightRender", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+		cdp := services.NewChromedpService(context.Background(), logger)
+		cdp.RunChromeDp()
+		hs := services.NewHtml2PdfService(logger, cdp)
+
+		request := dtos.HtmlRequest{PrintOptions: auto, Content: `<html><body style="margin:0"><div style="height:2000px">receipt</div></body></html>`}
+		response, err := hs.HtmlToPdfContext(context.Background(), request)
+		skipWithoutBrowser(t, err)
+
+		assert.NoError(t, err)
+		doc, err := pdf.Parse(response.Content)
+		if assert.NoError(t, err) {
+			assert.Equal(t, 1, doc.NumPages())
+		}
+	})
+
+	t.Run("TestAutoHeightAboveMaximum", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+		cdp := services.NewChromedpService(context.Background(), logger)
+		cdp.RunChromeDp()
+		hs := services.NewHtml2PdfServiceWithMaxAutoHeight(logger, cdp, 10)
+
+		request := dtos.HtmlRequest{PrintOptions: auto, Content: `<html><body style="margin:0"><div style="height:2000px">receipt</div></body></html>`}
+		_, err := hs.HtmlToPdfContext(context.Background(), request)
+		skipWithoutBrowser(t, err)
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+}
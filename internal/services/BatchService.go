@@ -0,0 +1,177 @@
+package services
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
+)
+
+// BatchManifest is the name of the archive entry listing the outcome of
+// each item of a batch.
+const BatchManifest = "manifest.json"
+
+type batchItem struct {
+	index   int
+	content []byte
+	err     error
+}
+
+// batchService renders the items of a batch concurrently into a ZIP
+// archive, up to a configured number at once.
+type batchService struct {
+	logger          logger.Logger
+	html2PdfService interfaces.Html2PdfServiceInterface
+	concurrency     int
+	maxItems        int
+	maxPdfSize      int64
+}
+
+func NewBatchService(l logger.Logger, html2PdfService interfaces.Html2PdfServiceInterface) interfaces.BatchServiceInterface {
+	return newBatchService(l, html2PdfService, configs.GetConfig().Batch, configs.GetConfig().Pdf.MaxSize)
+}
+
+func newBatchService(l logger.Logger, html2PdfService interfaces.Html2PdfServiceInterface, cfg configs.Batch, maxPdfSize int64) *batchService {
+	concurrency := cfg.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &batchService{
+		logger:          l,
+		html2PdfService: html2PdfService,
+		concurrency:     concurrency,
+		maxItems:        cfg.MaxItems,
+		maxPdfSize:      maxPdfSize,
+	}
+}
+
+// Check refuses a batch with more items than configured.
+func (s *batchService) Check(items []dtos.HtmlRequest) error {
+	if s.maxItems > 0 && len(items) > s.maxItems {
+		return fmt.Errorf("%w: a batch has at most %d items", ErrInvalidRequest, s.maxItems)
+	}
+	return nil
+}
+
+// Render writes to w a ZIP archive of the pdfs of the items, in the order
+// they are done, followed by the manifest. A failed item is only reported
+// in the manifest, the returned error is about writing the archive.
+func (s *batchService) Render(ctx context.Context, items []dtos.HtmlRequest, w io.Writer) ([]dtos.BatchItemResult, error) {
+	results := make([]dtos.BatchItemResult, len(items))
+	for i, name := range batchFilenames(items) {
+		results[i] = dtos.BatchItemResult{Index: i, Filename: name}
+	}
+	return s.writeZip(ctx, items, results, w)
+}
+
+// writeZip renders the items into a ZIP archive, recording their outcome
+// in their results.
+func (s *batchService) writeZip(ctx context.Context, items []dtos.HtmlRequest, results []dtos.BatchItemResult, w io.Writer) ([]dtos.BatchItemResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan batchItem)
+	go s.renderAll(ctx, items, done)
+
+	archive := zip.NewWriter(w)
+	var writeErr error
+	for item := range done {
+		result := &results[item.index]
+		if item.err != nil {
+			result.Error = item.err.Error()
+			s.logger.Warn("Batch item failed", zap.Int("index", item.index), zap.Error(item.err))
+			continue
+		}
+		if writeErr != nil {
+			continue
+		}
+		if writeErr = writeZipEntry(archive, result.Filename, item.content); writeErr != nil {
+			// nothing more can be written, the remaining items are dropped
+			cancel()
+			continue
+		}
+		result.Success = true
+		result.Size = len(item.content)
+	}
+	if writeErr != nil {
+		return results, writeErr
+	}
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+
+	manifest, _ := json.MarshalIndent(results, "", "  ")
+	if err := writeZipEntry(archive, BatchManifest, manifest); err != nil {
+		return results, err
+	}
+	return results, archive.Close()
+}
+
+// renderAll renders the items with at most concurrency of them at once,
+// sending each outcome to done, which is closed once all are sent.
+func (s *batchService) renderAll(ctx context.Context, items []dtos.HtmlRequest, done chan<- batchItem) {
+	var wg sync.WaitGroup
+	defer close(done)
+	defer wg.Wait()
+
+	slots := make(chan struct{}, s.concurrency)
+	for i, item := range items {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		wg.Add(1)
+		go func(i int, item dtos.HtmlRequest) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			response, err := s.html2PdfService.HtmlToPdfContext(ctx, item)
+			if err == nil && len(response.Content) == 0 {
+				err = fmt.Errorf("empty pdf")
+			}
+			done <- batchItem{index: i, content: response.Content, err: err}
+		}(i, item)
+	}
+}
+
+func writeZipEntry(archive *zip.Writer, name string, content []byte) error {
+	entry, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = entry.Write(content)
+	return err
+}
+
+// batchFilenames names the archive entries of the items after their
+// Filename, numbering the unnamed ones and the duplicates.
+func batchFilenames(items []dtos.HtmlRequest) []string {
+	names := make([]string, len(items))
+	used := map[string]bool{BatchManifest: true}
+	for i, item := range items {
+		name := path.Base(strings.ReplaceAll(strings.TrimSpace(item.Filename), "\\", "/"))
+		if name == "." || name == "/" || name == ".." {
+			name = fmt.Sprintf("document-%d", i+1)
+		}
+		if !strings.EqualFold(path.Ext(name), ".pdf") {
+			name += ".pdf"
+		}
+		ext := path.Ext(name)
+		base, candidate := strings.TrimSuffix(name, ext), name
+		for n := 2; used[strings.ToLower(candidate)]; n++ {
+			candidate = fmt.Sprintf("%s-%d%s", base, n, ext)
+		}
+		used[strings.ToLower(candidate)] = true
+		names[i] = candidate
+	}
+	return names
+}
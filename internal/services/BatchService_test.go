@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func readArchive(t *testing.T, content []byte) map[string][]byte {
+	archive, err := zip.NewReader(bytes.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+	files := map[string][]byte{}
+	for _, f := range archive.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		files[f.Name], _ = io.ReadAll(rc)
+		rc.Close()
+	}
+	return files
+}
+
+func TestBatchService(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TestRender", func(t *testing.T) {
+		var running, peak int32
+		bs := services.NewBatchServiceWith(renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			if request.Content == "bad" {
+				return dtos.PdfResponse{}, errors.New("boom")
+			}
+			return dtos.PdfResponse{Content: []byte(request.Content)}, nil
+		}}, configs.Batch{Concurrency: 2})
+
+		items := []dtos.HtmlRequest{
+			{Content: "%PDF-a", Filename: "a"},
+			{Content: "bad", Filename: "b.pdf"},
+			{Content: "%PDF-c", Filename: "a"},
+			{Content: "%PDF-d"},
+		}
+		var out bytes.Buffer
+		results, err := bs.Render(context.Background(), items, &out)
+		assert.NoError(t, err)
+		assert.LessOrEqual(t, peak, int32(2))
+
+		files := readArchive(t, out.Bytes())
+		assert.Equal(t, []byte("%PDF-a"), files["a.pdf"])
+		assert.Equal(t, []byte("%PDF-c"), files["a-2.pdf"])
+		assert.Equal(t, []byte("%PDF-d"), files["document-4.pdf"])
+		assert.NotContains(t, files, "b.pdf")
+
+		var manifest []dtos.BatchItemResult
+		assert.NoError(t, json.Unmarshal(files[services.BatchManifest], &manifest))
+		assert.Equal(t, results, manifest)
+		assert.True(t, manifest[0].Success)
+		assert.False(t, manifest[1].Success)
+		assert.Equal(t, "boom", manifest[1].Error)
+		assert.Equal(t, 6, manifest[3].Size)
+	})
+
+	t.Run("TestRenderCanceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		bs := services.NewBatchServiceWith(renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+			cancel()
+			return dtos.PdfResponse{}, ctx.Err()
+		}}, configs.Batch{Concurrency: 1})
+
+		_, err := bs.Render(ctx, []dtos.HtmlRequest{{}, {}}, io.Discard)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("TestCheck", func(t *testing.T) {
+		bs := services.NewBatchServiceWith(renderStub{}, configs.Batch{MaxItems: 1})
+
+		assert.NoError(t, bs.Check([]dtos.HtmlRequest{{}}))
+		assert.ErrorIs(t, bs.Check([]dtos.HtmlRequest{{}, {}}), services.ErrInvalidRequest)
+	})
+
+	t.Run("TestBatchFilenames", func(t *testing.T) {
+		names := services.BatchFilenames([]dtos.HtmlRequest{
+			{Filename: "../../etc/report"},
+			{Filename: "Report.PDF"},
+			{Filename: "manifest.json"},
+			{},
+		})
+
+		assert.Equal(t, []string{"report.pdf", "Report-2.PDF", "manifest.json.pdf", "document-4.pdf"}, names)
+	})
+}
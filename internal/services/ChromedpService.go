@@ -2,76 +2,447 @@ package services
 
 import (
 	"context"
-	"io"
+	"errors"
+	"fmt"
 	"log"
-	"net/http"
-	"net/http/httptest"
-	"strings"
+	"sort"
+	"sync"
+	"time"
 
 	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/configs"
 	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
 )
 
-const content = `
-<body>
-<script>
-	// Show the current cookies.
-	var p = document.createElement("p")
-	p.innerText = document.cookie
-	p.setAttribute("id", "cookies")
-	document.body.appendChild(p)
-	// Override the cookies.
-	document.cookie = "foo=bar"
-</script>
-</body>
-	`
+const (
+	BalancerLeastBusy  = "least-busy"
+	BalancerRoundRobin = "round-robin"
+)
+
+var ErrPoolUnavailable = errors.New("no browser of the pool could be started")
 
+// ChromedpService owns a pool of long-lived browsers and hands out isolated
+// tabs to concurrent renders. Browsers are launched locally, or attached to
+// over the DevTools protocol when remote endpoints are configured, in which
+// case every endpoint is one browser of the pool.
 type ChromedpService struct {
 	logger  logger.Logger
 	Context context.Context
 	Cancelf context.CancelFunc
-	Content string
+
+	parent            context.Context
+	ctx               context.Context
+	execPath          string
+	endpoints         []string
+	balancer          string
+	tabsPerBrowser    int
+	reconnectAttempts int
+	reconnectInterval time.Duration
+	healthInterval    time.Duration
+	recycleRenders    int
+	recycleAge        time.Duration
+	renderRetries     int
+	tabTimeout        time.Duration
+	browsers          []*pooledBrowser
+	next              int
+	slots             chan struct{}
+	lock              *sync.Mutex
+}
+
+// pooledBrowser is one browser of the pool: a local process, or the
+// connection to a remote endpoint. Unhealthy and draining browsers only get
+// tabs once every other one has been tried.
+type pooledBrowser struct {
+	endpoint  string
+	ctx       context.Context
+	cancel    context.CancelFunc
+	busy      int
+	renders   int
+	startedAt time.Time
+	healthy   bool
+	draining  bool
+	// tabFailures counts the tabs in a row that could not be opened
+	tabFailures int
+	starting    *sync.Mutex
 }
 
 func NewChromedpService(c context.Context, l logger.Logger) *ChromedpService {
+	cfg := configs.GetConfig().Chrome
+
+	// a local pool is made of anonymous endpoints
+	endpoints := cfg.RemoteURLs
+	if len(endpoints) == 0 {
+		poolSize := cfg.PoolSize
+		if poolSize < 1 {
+			poolSize = 1
+		}
+		endpoints = make([]string, poolSize)
+	}
+	tabsPerBrowser := cfg.TabsPerBrowser
+	if tabsPerBrowser < 1 {
+		tabsPerBrowser = 1
+	}
+
+	reconnectAttempts := cfg.ReconnectAttempts
+	if reconnectAttempts < 1 {
+		reconnectAttempts = 1
+	}
+	tabTimeout := cfg.TabTimeout
+	if tabTimeout <= 0 {
+		tabTimeout = 10 * time.Second
+	}
+
 	obj := &ChromedpService{
-		logger: l,
+		logger:            l,
+		parent:            c,
+		execPath:          cfg.ExecPath,
+		endpoints:         endpoints,
+		balancer:          cfg.Balancer,
+		tabsPerBrowser:    tabsPerBrowser,
+		reconnectAttempts: reconnectAttempts,
+		reconnectInterval: cfg.ReconnectInterval,
+		healthInterval:    cfg.HealthCheckInterval,
+		recycleRenders:    cfg.RecycleAfterRenders,
+		recycleAge:        cfg.RecycleAfter,
+		renderRetries:     cfg.RenderRetries,
+		tabTimeout:        tabTimeout,
+		slots:             make(chan struct{}, len(endpoints)*tabsPerBrowser),
+		lock:              &sync.Mutex{},
 	}
 
 	return obj
 }
 
+// RunChromeDp starts every browser of the pool. Browsers that fail to start
+// are marked unhealthy and started again by the health check, or the next
+// time no healthy browser is left to hand out a tab.
 func (c *ChromedpService) RunChromeDp() error {
-	c.Content = content
+	c.lock.Lock()
+	browsers := c.setup()
+	c.lock.Unlock()
+
+	var wg sync.WaitGroup
+	errs := make([]error, len(browsers))
+	for i, b := range browsers {
+		wg.Add(1)
+		go func(i int, b *pooledBrowser) {
+			defer wg.Done()
+			errs[i] = c.startBrowser(b, c.reconnectAttempts)
+		}(i, b)
+	}
+	wg.Wait()
 
-	ts := httptest.NewServer(c.WriteHTML())
+	started := 0
+	for i, err := range errs {
+		if err != nil {
+			c.logger.Error("Error starting pooled browser", zap.String("endpoint", browsers[i].endpoint), zap.Error(err))
+			continue
+		}
+		started++
+	}
+
+	if started == 0 {
+		return ErrPoolUnavailable
+	}
+	c.logger.Info("Browser pool started", zap.Int("browsers", started))
 
-	defer ts.Close()
+	return nil
+}
+
+// NewTab hands out a tab, in its own browser context, on a browser of the
+// pool chosen by the configured balancer. It blocks until the pool has a
+// free slot or ctx is done. The returned release func closes the tab and
+// frees the slot.
+func (c *ChromedpService) NewTab(ctx context.Context) (context.Context, func(), error) {
+	tab, err := c.newTab(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tab.ctx, tab.release, nil
+}
+
+// pooledTab is a tab handed out by the pool and the browser it was opened
+// on.
+type pooledTab struct {
+	browser    *pooledBrowser
+	browserCtx context.Context
+	ctx        context.Context
+	release    func()
+}
+
+func (c *ChromedpService) newTab(ctx context.Context) (*pooledTab, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	select {
+	case c.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	b, browserCtx, err := c.acquire()
+	if err != nil {
+		<-c.slots
+		return nil, err
+	}
+
+	tabCtx, cancel := chromedp.NewContext(browserCtx, chromedp.WithNewBrowserContext())
+	release := func() {
+		cancel()
+		c.lock.Lock()
+		b.busy--
+		b.renders++
+		// take b out of the pool before the slot is freed, so no tab is
+		// handed out on the browser about to be recycled
+		var stop context.CancelFunc
+		recycle := c.expired(b) && b.busy == 0
+		if recycle {
+			stop = c.detach(b)
+		}
+		c.lock.Unlock()
+		<-c.slots
+
+		if recycle {
+			go c.recycle(b, stop)
+		}
+	}
+
+	return &pooledTab{
+		browser:    b,
+		browserCtx: browserCtx,
+		ctx:        tabCtx,
+		release:    release,
+	}, nil
+}
+
+// setup creates the browsers of the pool under a new root context and starts
+// their health check. The caller must hold c.lock.
+func (c *ChromedpService) setup() []*pooledBrowser {
+	if c.Cancelf != nil {
+		c.Cancelf()
+	}
+	c.ctx, c.Cancelf = context.WithCancel(c.parent)
+	c.Context = c.ctx
+
+	c.browsers = make([]*pooledBrowser, len(c.endpoints))
+	for i, endpoint := range c.endpoints {
+		c.browsers[i] = &pooledBrowser{
+			endpoint: endpoint,
+			starting: &sync.Mutex{},
+		}
+	}
+
+	if c.healthInterval > 0 {
+		go c.healthCheck(c.ctx, c.browsers)
+	}
+
+	return c.browsers
+}
 
-	taskCtx, cancel := chromedp.NewContext(
-		context.Background(),
+// acquire walks the browsers in balancer order, starting again (or
+// reconnecting to) the ones that are gone, and reserves a tab on the first
+// browser that is running.
+func (c *ChromedpService) acquire() (*pooledBrowser, context.Context, error) {
+	c.lock.Lock()
+	if c.browsers == nil {
+		c.setup()
+	}
+	candidates := c.candidates()
+	c.lock.Unlock()
+
+	var err error
+	for _, b := range candidates {
+		if err = c.startBrowser(b, 1); err != nil {
+			c.logger.Warn("Pooled browser unavailable", zap.String("endpoint", b.endpoint), zap.Error(err))
+			continue
+		}
+
+		c.lock.Lock()
+		browserCtx := b.ctx
+		if browserCtx == nil || browserCtx.Err() != nil {
+			c.lock.Unlock()
+			continue
+		}
+		b.busy++
+		c.lock.Unlock()
+
+		return b, browserCtx, nil
+	}
+
+	return nil, nil, fmt.Errorf("%w: %v", ErrPoolUnavailable, err)
+}
+
+// candidates orders the browsers of the pool: healthy ones first, sorted by
+// the balancer, then the unhealthy and draining ones. The caller must hold
+// c.lock.
+func (c *ChromedpService) candidates() []*pooledBrowser {
+	var healthy, unhealthy []*pooledBrowser
+
+	n := len(c.browsers)
+	for i := 0; i < n; i++ {
+		b := c.browsers[(c.next+i)%n]
+		if b.healthy && !c.expired(b) {
+			healthy = append(healthy, b)
+		} else {
+			unhealthy = append(unhealthy, b)
+		}
+	}
+	c.next = (c.next + 1) % n
+
+	if c.balancer != BalancerRoundRobin {
+		sort.SliceStable(healthy, func(i, j int) bool {
+			return healthy[i].busy < healthy[j].busy
+		})
+	}
+
+	return append(healthy, unhealthy...)
+}
+
+// startBrowser starts b when it is not running, making up to attempts tries
+// before marking it unhealthy. Local processes are launched only once.
+func (c *ChromedpService) startBrowser(b *pooledBrowser, attempts int) error {
+	if b.endpoint == "" {
+		attempts = 1
+	}
+
+	b.starting.Lock()
+	defer b.starting.Unlock()
+
+	c.lock.Lock()
+	root := c.ctx
+	if b.ctx != nil && b.ctx.Err() == nil {
+		c.lock.Unlock()
+		return nil
+	}
+	if b.cancel != nil {
+		b.cancel()
+	}
+	c.lock.Unlock()
+
+	var ctx context.Context
+	var cancel context.CancelFunc
+	var err error
+	for attempt := 1; ; attempt++ {
+		if ctx, cancel, err = c.runBrowser(root, b.endpoint); err == nil {
+			break
+		}
+		if attempt >= attempts {
+			c.lock.Lock()
+			b.healthy = false
+			c.lock.Unlock()
+			return err
+		}
+		c.logger.Warn("Error starting pooled browser", zap.String("endpoint", b.endpoint), zap.Int("attempt", attempt), zap.Error(err))
+
+		select {
+		case <-time.After(c.reconnectInterval):
+		case <-root.Done():
+			return root.Err()
+		}
+	}
+
+	c.lock.Lock()
+	b.ctx, b.cancel, b.healthy = ctx, cancel, true
+	b.renders, b.startedAt, b.draining, b.tabFailures = 0, time.Now(), false, 0
+	c.lock.Unlock()
+
+	go c.watch(root, b, ctx)
+
+	return nil
+}
+
+func (c *ChromedpService) runBrowser(root context.Context, endpoint string) (context.Context, context.CancelFunc, error) {
+	var allocCtx context.Context
+	var allocCancel context.CancelFunc
+	if endpoint != "" {
+		allocCtx, allocCancel = chromedp.NewRemoteAllocator(root, endpoint)
+	} else {
+		opts := chromedp.DefaultExecAllocatorOptions[:]
+		if c.execPath != "" {
+			opts = append(opts, chromedp.ExecPath(c.execPath))
+		}
+		allocCtx, allocCancel = chromedp.NewExecAllocator(root, opts...)
+	}
+
+	browserCtx, browserCancel := chromedp.NewContext(
+		allocCtx,
 		chromedp.WithLogf(log.Printf),
-		// chromedp.WithDebugf(log.Printf),
 	)
-	c.Cancelf = cancel
-	c.Context = taskCtx
-	chromedp.Flag("headless", false)
-	chromedp.Flag("disable-gpu", false)
-	chromedp.Flag("enable-automation", false)
-	chromedp.Flag("disable-extensions", false)
+	cancel := func() {
+		browserCancel()
+		allocCancel()
+	}
 
-	if err := chromedp.Run(taskCtx); err != nil {
-		c.logger.Error(err.Error())
+	// ensure that the browser process is started
+	if err := chromedp.Run(browserCtx); err != nil {
+		cancel()
+		return nil, nil, err
 	}
 
-	return nil
+	return browserCtx, cancel, nil
 }
 
-func (c *ChromedpService) WriteHTML() http.Handler {
-	return http.HandlerFunc(c.DoHandler)
+// watch marks b unhealthy as soon as its browser is gone and reconnects to
+// it in the background. Browsers replaced on purpose are left alone.
+func (c *ChromedpService) watch(root context.Context, b *pooledBrowser, ctx context.Context) {
+	<-ctx.Done()
+	if root.Err() != nil {
+		return
+	}
+
+	c.lock.Lock()
+	replaced := b.ctx != ctx
+	if !replaced {
+		b.healthy = false
+	}
+	c.lock.Unlock()
+	if replaced {
+		return
+	}
+	c.logger.Warn("Lost pooled browser, reconnecting", zap.String("endpoint", b.endpoint))
+
+	if err := c.startBrowser(b, c.reconnectAttempts); err != nil {
+		c.logger.Error("Error reconnecting pooled browser", zap.String("endpoint", b.endpoint), zap.Error(err))
+		return
+	}
+	c.logger.Info("Pooled browser reconnected", zap.String("endpoint", b.endpoint))
 }
 
-func (c *ChromedpService) DoHandler(w http.ResponseWriter, h *http.Request) {
-	w.Header().Set("Content-Type", "text/html")
-	io.WriteString(w, strings.TrimSpace(c.Content))
+// healthCheck periodically tries to start the unhealthy browsers again, so
+// endpoints that recover are admitted back into the pool, and recycles the
+// idle browsers that outlived their lifetime.
+func (c *ChromedpService) healthCheck(root context.Context, browsers []*pooledBrowser) {
+	ticker := time.NewTicker(c.healthInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-root.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for _, b := range browsers {
+			var stop context.CancelFunc
+			c.lock.Lock()
+			healthy := b.healthy
+			idle := healthy && b.busy == 0 && c.expired(b)
+			if idle {
+				stop = c.detach(b)
+			}
+			c.lock.Unlock()
+			if idle {
+				c.recycle(b, stop)
+				continue
+			}
+			if healthy {
+				continue
+			}
+			if err := c.startBrowser(b, 1); err == nil {
+				c.logger.Info("Pooled browser admitted back", zap.String("endpoint", b.endpoint))
+			}
+		}
+	}
 }
@@ -0,0 +1,65 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChromedpService(t *testing.T) {
+
+	t.Run("NewChromedpService", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+
+		cdp := services.NewChromedpService(context.Background(), logger)
+
+		assert.NotNil(t, cdp)
+	})
+
+	t.Run("TestNewTabContextDone", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+
+		cdp := services.NewChromedpService(context.Background(), logger)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		tabCtx, release, err := cdp.NewTab(ctx)
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Nil(t, tabCtx)
+		assert.Nil(t, release)
+	})
+
+	t.Run("TestRunContextDone", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+
+		cdp := services.NewChromedpService(context.Background(), logger)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		called := false
+		err := cdp.Run(ctx, func(ctx context.Context) error {
+			called = true
+			return nil
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.False(t, called)
+	})
+
+	t.Run("TestRunChromeDpSetsContext", func(t *testing.T) {
+		logger := logger.NewFakeLogger()
+
+		cdp := services.NewChromedpService(context.Background(), logger)
+		cdp.RunChromeDp()
+		defer cdp.Cancelf()
+
+		assert.NotNil(t, cdp.Context)
+		assert.NotNil(t, cdp.Cancelf)
+	})
+}
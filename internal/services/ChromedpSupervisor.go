@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chromedp/cdproto/inspector"
+	"github.com/chromedp/chromedp"
+	"go.uber.org/zap"
+)
+
+var ErrBrowserCrashed = errors.New("browser crashed while rendering")
+
+// maxTabFailures is the number of tabs in a row a browser may fail to open
+// before it is considered wedged and restarted.
+const maxTabFailures = 3
+
+// Run runs fn on a tab of the pool. When the tab or its browser crashes, or
+// the tab cannot be opened in time, fn is retried on another tab, up to the
+// configured number of retries. A browser is restarted only once it failed
+// to open maxTabFailures tabs in a row, as the restart cancels its other
+// renders. Errors returned by fn itself are not retried.
+func (c *ChromedpService) Run(ctx context.Context, fn func(ctx context.Context) error) error {
+	for attempt := 1; ; attempt++ {
+		err := c.runOnce(ctx, fn)
+		if !errors.Is(err, ErrBrowserCrashed) || attempt > c.renderRetries {
+			return err
+		}
+		c.logger.Warn("Render crashed, retrying on a healthy browser", zap.Int("attempt", attempt), zap.Error(err))
+	}
+}
+
+func (c *ChromedpService) runOnce(ctx context.Context, fn func(ctx context.Context) error) error {
+	tab, err := c.newTab(ctx)
+	if err != nil {
+		return err
+	}
+	defer tab.release()
+	b := tab.browser
+
+	// ensure that the tab is created, a browser that cannot open it in
+	// time is considered wedged
+	openCtx, cancelOpen := context.WithTimeout(tab.ctx, c.tabTimeout)
+	err = chromedp.Run(openCtx)
+	cancelOpen()
+
+	c.lock.Lock()
+	if err == nil {
+		b.tabFailures = 0
+	} else {
+		b.tabFailures++
+	}
+	wedged := b.tabFailures >= maxTabFailures
+	c.lock.Unlock()
+
+	if err != nil {
+		if !wedged {
+			c.logger.Warn("Browser tab isn't created", zap.String("endpoint", b.endpoint), zap.Error(err))
+			return fmt.Errorf("%w: %v", ErrBrowserCrashed, err)
+		}
+		c.logger.Error("Browser tabs aren't created, restarting browser", zap.String("endpoint", b.endpoint), zap.Error(err))
+		c.restart(b)
+		return fmt.Errorf("%w: %v", ErrBrowserCrashed, err)
+	}
+
+	crashCtx, crash := context.WithCancelCause(tab.ctx)
+	defer crash(nil)
+	chromedp.ListenTarget(crashCtx, func(ev interface{}) {
+		if _, ok := ev.(*inspector.EventTargetCrashed); ok {
+			crash(ErrBrowserCrashed)
+		}
+	})
+
+	err = fn(crashCtx)
+
+	switch {
+	case errors.Is(context.Cause(crashCtx), ErrBrowserCrashed):
+		// the renderer is gone, do not trust the rest of the process
+		c.logger.Error("Browser tab crashed", zap.String("endpoint", b.endpoint))
+		c.drain(b)
+		return ErrBrowserCrashed
+	case tab.browserCtx.Err() != nil:
+		c.logger.Error("Browser crashed while rendering", zap.String("endpoint", b.endpoint))
+		return ErrBrowserCrashed
+	}
+
+	return err
+}
+
+// expired reports whether b served enough renders, or lived long enough,
+// to be recycled. The caller must hold c.lock.
+func (c *ChromedpService) expired(b *pooledBrowser) bool {
+	if b.draining {
+		return true
+	}
+	if c.recycleRenders > 0 && b.renders >= c.recycleRenders {
+		return true
+	}
+	return c.recycleAge > 0 && !b.startedAt.IsZero() && time.Since(b.startedAt) >= c.recycleAge
+}
+
+// drain stops handing out tabs of b and recycles it once its last tab is
+// released.
+func (c *ChromedpService) drain(b *pooledBrowser) {
+	c.lock.Lock()
+	b.draining = true
+	c.lock.Unlock()
+}
+
+// recycle restarts b, bounding the memory a long-lived browser can leak. b
+// must have been detached, stop is the func detach returned.
+func (c *ChromedpService) recycle(b *pooledBrowser, stop context.CancelFunc) {
+	c.logger.Info("Recycling pooled browser", zap.String("endpoint", b.endpoint))
+	c.relaunch(b, stop)
+}
+
+func (c *ChromedpService) restart(b *pooledBrowser) {
+	c.lock.Lock()
+	stop := c.detach(b)
+	c.lock.Unlock()
+
+	c.relaunch(b, stop)
+}
+
+// detach takes the running browser out of b, so that no more tabs are
+// handed out on it, and returns the func stopping it. The caller must hold
+// c.lock.
+func (c *ChromedpService) detach(b *pooledBrowser) context.CancelFunc {
+	stop := b.cancel
+	b.ctx, b.cancel = nil, nil
+	return stop
+}
+
+// relaunch stops the browser detached from b and starts a new one.
+func (c *ChromedpService) relaunch(b *pooledBrowser, stop context.CancelFunc) {
+	if stop != nil {
+		stop()
+	}
+	if err := c.startBrowser(b, c.reconnectAttempts); err != nil {
+		c.logger.Error("Error restarting pooled browser", zap.String("endpoint", b.endpoint), zap.Error(err))
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+const (
+	defaultViewportWidth  = 1280
+	defaultViewportHeight = 800
+)
+
+var imageFormats = map[string]page.CaptureScreenshotFormat{
+	dtos.ImageFormatPng:  page.CaptureScreenshotFormatPng,
+	dtos.ImageFormatJpeg: page.CaptureScreenshotFormatJpeg,
+	dtos.ImageFormatWebp: page.CaptureScreenshotFormatWebp,
+}
+
+func (r *html2PdfService) HtmlToImage(request dtos.ImageRequest) (dtos.ImageResponse, error) {
+	resp := new(dtos.ImageResponse)
+	err := r.render(context.Background(), request.HtmlRequest, func(url string, htmlRequest dtos.HtmlRequest) chromedp.Tasks {
+		request.HtmlRequest = htmlRequest
+		return r.imageGrabber(url, resp, request)
+	}, func() bool {
+		return len(resp.Content) > 0
+	})
+	return *resp, err
+}
+
+// imageGrabber loads the request content in a viewport of the requested
+// size and takes its screenshot into resp.
+func (r *html2PdfService) imageGrabber(url string, resp *dtos.ImageResponse, request dtos.ImageRequest) chromedp.Tasks {
+	return chromedp.Tasks{
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			*resp = dtos.ImageResponse{}
+			return nil
+		}),
+		r.viewportActions(request),
+		r.pageTasks(url, request.HtmlRequest, &resp.Scripts, &resp.BlockedRequests,
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				return r.screenshot(ctx, resp, request)
+			})),
+	}
+}
+
+func (r *html2PdfService) viewportActions(request dtos.ImageRequest) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		width, height, scale := request.ViewportWidth, request.ViewportHeight, request.DeviceScaleFactor
+		if width == 0 {
+			width = defaultViewportWidth
+		}
+		if height == 0 {
+			height = defaultViewportHeight
+		}
+		if scale == 0 {
+			scale = 1
+		}
+		if err := emulation.SetDeviceMetricsOverride(width, height, scale, false).Do(ctx); err != nil {
+			return err
+		}
+		if request.Transparent && imageFormat(request) != dtos.ImageFormatJpeg {
+			return emulation.SetDefaultBackgroundColorOverride().
+				WithColor(&cdp.RGBA{R: 0, G: 0, B: 0, A: 0}).
+				Do(ctx)
+		}
+		return nil
+	}
+}
+
+func (r *html2PdfService) screenshot(ctx context.Context, resp *dtos.ImageResponse, request dtos.ImageRequest) error {
+	format := imageFormat(request)
+	capture := page.CaptureScreenshot().
+		WithFormat(imageFormats[format]).
+		WithFromSurface(true)
+	if request.Quality > 0 && format != dtos.ImageFormatPng {
+		capture = capture.WithQuality(request.Quality)
+	}
+
+	switch {
+	case request.FullPage:
+		_, _, _, _, _, contentSize, err := page.GetLayoutMetrics().Do(ctx)
+		if err != nil {
+			return err
+		}
+		capture = capture.
+			WithCaptureBeyondViewport(true).
+			WithClip(&page.Viewport{
+				Width:  math.Ceil(contentSize.Width),
+				Height: math.Ceil(contentSize.Height),
+				Scale:  1,
+			})
+	case request.Selector != "":
+		clip, err := selectorClip(ctx, request.Selector)
+		if err != nil {
+			return err
+		}
+		capture = capture.WithCaptureBeyondViewport(true).WithClip(clip)
+	}
+
+	buf, err := capture.Do(ctx)
+	if err != nil {
+		return err
+	}
+	resp.Content = buf
+	resp.ContentType = "image/" + format
+	return nil
+}
+
+// selectorClip is the area of the document covered by the first element
+// matching selector.
+func selectorClip(ctx context.Context, selector string) (*page.Viewport, error) {
+	var rect *struct {
+		X, Y, Width, Height float64
+	}
+	quoted, _ := json.Marshal(selector)
+	expression := fmt.Sprintf(`(function (selector) {
+		const element = document.querySelector(selector);
+		if (!element) {
+			return null;
+		}
+		const rect = element.getBoundingClientRect();
+		return { X: rect.left + window.scrollX, Y: rect.top + window.scrollY, Width: rect.width, Height: rect.height };
+	})(%s)`, quoted)
+	if err := chromedp.Evaluate(expression, &rect).Do(ctx); err != nil {
+		return nil, err
+	}
+	if rect == nil || rect.Width == 0 || rect.Height == 0 {
+		return nil, fmt.Errorf("%w: selector %q matched no visible element", ErrInvalidRequest, selector)
+	}
+	return &page.Viewport{X: rect.X, Y: rect.Y, Width: rect.Width, Height: rect.Height, Scale: 1}, nil
+}
+
+func imageFormat(request dtos.ImageRequest) string {
+	if request.Format == "" {
+		return dtos.ImageFormatPng
+	}
+	return request.Format
+}
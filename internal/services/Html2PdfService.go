@@ -1,103 +1,235 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"path"
 	"strings"
 	"sync"
 	"time"
 
-	"github.com/chromedp/cdproto/cdp"
 	"github.com/chromedp/cdproto/page"
-	"github.com/chromedp/cdproto/runtime"
 	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/configs"
 	"github.com/kolzxx/html2pdf/internal/dtos"
 	"github.com/kolzxx/html2pdf/internal/interfaces"
 	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
 )
 
+// ErrRenderTimeout is returned when the page could not be rendered before
+// the render deadline.
+var ErrRenderTimeout = errors.New("render timed out")
+
 type html2PdfService struct {
 	logger          logger.Logger
-	Content         string
-	ContentCss      string
-	WaitElementId   string
 	chromedpService *ChromedpService
-	lock            *sync.Mutex
+	scriptsDir      string
+	insertsDir      string
+	templates       *templateService
+	schemasDir      string
+	profiles        *profileService
+	networkPolicy   *networkPolicy
+	urlHosts        []string
+	maxPdfSize      int64
+	maxAutoHeight   float64
+	renderTimeout   time.Duration
 }
 
 func NewHtml2PdfService(l logger.Logger, chromedpService *ChromedpService) interfaces.Html2PdfServiceInterface {
+	// the server refuses to start with an unknown template backend, the
+	// registry templates are unavailable until then
+	var templates *templateService
+	if store, err := newTemplateStore(configs.GetConfig().Templates); err == nil {
+		templates = newTemplateService(store)
+	} else {
+		l.Error("Error opening the template registry", zap.Error(err))
+	}
+
 	obj := &html2PdfService{
 		logger:          l,
 		chromedpService: chromedpService,
-		lock:            &sync.Mutex{},
+		scriptsDir:      configs.GetConfig().Scripts.Dir,
+		insertsDir:      configs.GetConfig().Pdf.InsertsDir,
+		templates:       templates,
+		schemasDir:      configs.GetConfig().Schemas.Dir,
+		profiles:        newProfileService(l, configs.GetConfig().Profiles.Dir),
+		networkPolicy:   newNetworkPolicy(configs.GetConfig().Network, l),
+		urlHosts:        lowerAll(configs.GetConfig().URLSource.AllowHosts),
+		maxPdfSize:      configs.GetConfig().Pdf.MaxSize,
+		maxAutoHeight:   configs.GetConfig().Pdf.MaxAutoHeight,
+		renderTimeout:   configs.GetConfig().Chrome.RenderTimeout,
 	}
 	return obj
 }
 
 func (r *html2PdfService) HtmlToPdf(request dtos.HtmlRequest) (dtos.PdfResponse, error) {
-	r.lock.Lock()
-	defer r.lock.Unlock()
+	return r.HtmlToPdfContext(context.Background(), request)
+}
 
+// HtmlToPdfContext renders the request until ctx is done, or for the
+// configured render timeout when ctx has no deadline.
+func (r *html2PdfService) HtmlToPdfContext(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
 	resp := new(dtos.PdfResponse)
-	r.WaitElementId = request.WaitElementId
-	r.Content = request.Content
-	r.Content = strings.ReplaceAll(r.Content, "\r\n", "\n")
-	r.Content = strings.ReplaceAll(r.Content, "\r", "")
-	r.ContentCss = request.ContentCss
-	r.ContentCss = strings.ReplaceAll(r.ContentCss, "\r\n", "\n")
-	r.ContentCss = strings.ReplaceAll(r.ContentCss, "\r", "")
+	err := r.render(ctx, request, func(url string, request dtos.HtmlRequest) chromedp.Tasks {
+		return r.grabber(url, resp, request)
+	}, func() bool {
+		return len(resp.Content) > 0
+	})
+	return *resp, err
+}
+
+// render prepares the request and runs the tasks built for it on a pooled
+// tab, trying them once more when they fail before done reports an output.
+// The render stops when ctx is done, or after the render timeout when ctx
+// has no deadline, which is reported as ErrRenderTimeout.
+func (r *html2PdfService) render(ctx context.Context, request dtos.HtmlRequest, tasks func(url string, request dtos.HtmlRequest) chromedp.Tasks, done func() bool) error {
+	request, err := r.resolveRequest(request)
+	if err != nil {
+		return err
+	}
+	request.Content = strings.ReplaceAll(request.Content, "\r\n", "\n")
+	request.Content = strings.ReplaceAll(request.Content, "\r", "")
 	request.ContentCss = strings.ReplaceAll(request.ContentCss, "\r\n", "\n")
 	request.ContentCss = strings.ReplaceAll(request.ContentCss, "\r", "")
+	request.BaseStylesheets = normalizeStylesheets(request.BaseStylesheets)
+	request.Stylesheets = normalizeStylesheets(request.Stylesheets)
 	request.FooterTemplate = strings.ReplaceAll(request.FooterTemplate, "\r\n", "\n")
 	request.HeaderTemplate = strings.ReplaceAll(request.HeaderTemplate, "\r\n", "\n")
 	request.FooterTemplate = strings.ReplaceAll(request.FooterTemplate, "\r", "")
 	request.HeaderTemplate = strings.ReplaceAll(request.HeaderTemplate, "\r", "")
 
-	ts := httptest.NewServer(r.WriteHTML())
-
-	defer ts.Close()
+	scripts, err := r.resolveScripts(request.Scripts)
+	if err != nil {
+		return err
+	}
+	request.Scripts = scripts
 
-	taskCtx, cancel := chromedp.NewContext(context.Background())
-	defer cancel()
+	inserts, err := r.resolveInserts(request)
+	if err != nil {
+		return err
+	}
+	request.Inserts = inserts
 
-	// ensure that the browser process is started
-	if err := chromedp.Run(taskCtx); err != nil {
-		r.logger.Error("browser process isn't started", err)
+	if request.URL != "" {
+		checkCtx, cancelCheck := context.WithTimeout(ctx, time.Second*5)
+		err := r.checkURL(checkCtx, request)
+		cancelCheck()
+		if err != nil {
+			return err
+		}
 	}
 
-	cxtt, cancelt := context.WithTimeout(taskCtx, time.Second*20)
-	defer cancelt()
+	ts := httptest.NewServer(r.WriteHTML(request))
 
-	var pdfBuffer []byte
+	defer ts.Close()
 
-	if err := chromedp.Run(cxtt,
-		r.PdfGrabber(ts.URL, &pdfBuffer, request)); err != nil {
-		if len(pdfBuffer) > 0 {
-			resp.Content = pdfBuffer
-			return *resp, nil
-		}
-		if err2 := chromedp.Run(cxtt,
-			r.PdfGrabber(ts.URL, &pdfBuffer, request),
-		); err2 != nil {
-			r.logger.Error("context timeout reached, attempting to perform actions", err)
-		}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(r.renderTimeout)
 	}
+	acquireCtx, cancelAcquire := context.WithDeadline(ctx, deadline)
+	defer cancelAcquire()
 
-	resp.Content = pdfBuffer
+	err = r.chromedpService.Run(acquireCtx, func(taskCtx context.Context) error {
+		cxtt, cancelt := context.WithDeadline(taskCtx, deadline)
+		defer cancelt()
+		stop := context.AfterFunc(ctx, cancelt)
+		defer stop()
 
-	return *resp, nil
+		if err := chromedp.Run(cxtt,
+			tasks(ts.URL, request)); err != nil {
+			var waitErr *WaitError
+			if done() {
+				return nil
+			}
+			if errors.As(err, &waitErr) || errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrOutputTooLarge) {
+				return err
+			}
+			if err2 := chromedp.Run(cxtt,
+				tasks(ts.URL, request),
+			); err2 != nil {
+				r.logger.Error("context timeout reached, attempting to perform actions", zap.Error(err))
+				return err2
+			}
+		}
+		return nil
+	})
+	if errors.Is(err, ErrPoolUnavailable) || errors.Is(err, ErrBrowserCrashed) {
+		r.logger.Error("browser unavailable", zap.Error(err))
+		return err
+	}
+	var waitErr *WaitError
+	if errors.As(err, &waitErr) {
+		r.logger.Warn("page not ready to print", zap.Error(err))
+		return err
+	}
+
+	if errors.Is(err, ErrInvalidRequest) || errors.Is(err, ErrOutputTooLarge) {
+		return err
+	}
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		r.logger.Error("render timed out", zap.Error(err))
+		return ErrRenderTimeout
+	}
+	r.logger.Error("render failed", zap.Error(err))
+	return fmt.Errorf("render failed: %w", err)
 }
 
-const (
-	makeVisibleScript = `setTimeout(function() { document.querySelector('#hash_assinatura').style.display = '';	}, 3000);`
-)
+// resolveRequest applies the profile of the request, builds its content
+// from its data and resolves its print options. The settings of the
+// request prevail over the ones of its profile, which prevail over the
+// ones of its template.
+func (r *html2PdfService) resolveRequest(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	request, err := r.applyProfile(request)
+	if err != nil {
+		return request, err
+	}
+	if request, err = r.resolveData(request); err != nil {
+		return request, err
+	}
+	return resolvePrintOptions(request)
+}
 
 func (r *html2PdfService) PdfGrabber(url string, res *[]byte, request dtos.HtmlRequest) chromedp.Tasks {
-	var nodes []*cdp.Node
+	resp := new(dtos.PdfResponse)
+	return append(r.grabber(url, resp, request), chromedp.ActionFunc(func(ctx context.Context) error {
+		*res = resp.Content
+		return nil
+	}))
+}
+
+// grabber loads the request content and prints it into resp, along with the
+// results of the request scripts.
+func (r *html2PdfService) grabber(url string, resp *dtos.PdfResponse, request dtos.HtmlRequest) chromedp.Tasks {
 	return chromedp.Tasks{
+		chromedp.ActionFunc(func(ctx context.Context) error {
+			*resp = dtos.PdfResponse{}
+			return nil
+		}),
+		r.pageTasks(url, request, &resp.Scripts, &resp.BlockedRequests,
+			chromedp.ActionFunc(r.pdfActions(&resp.Content, request))),
+	}
+}
+
+// pageTasks loads the request content, runs its scripts and waits for the
+// page to be ready before running finish. The script results and the
+// blocked requests are kept in scripts and blocked.
+func (r *html2PdfService) pageTasks(url string, request dtos.HtmlRequest, scripts *[]dtos.ScriptResult, blocked *[]dtos.BlockedRequest, finish chromedp.Action) chromedp.Tasks {
+	tracker := newNetworkTracker()
+	interceptor := newNetworkInterceptor(r.networkPolicy, r.logger, strings.TrimPrefix(url, "http://"), request, r.urlHosts)
+	load := chromedp.Tasks{
 		chromedp.Navigate(url),
 		chromedp.ActionFunc(func(ctx context.Context) error {
 			lctx, lcancel := context.WithCancel(ctx)
@@ -111,45 +243,33 @@ func (r *html2PdfService) PdfGrabber(url string, res *[]byte, request dtos.HtmlR
 					wg.Done()
 				}
 			})
-			_, exp, err := runtime.Evaluate(makeVisibleScript).Do(ctx)
-			if err != nil {
-				return err
-			}
-			if exp != nil {
-				return exp
-			}
 			frameTree, err := page.GetFrameTree().Do(ctx)
 			if err != nil {
 				return err
 			}
 			r.logger.Info("Getting Frame Tree finish")
-			if len(r.ContentCss) > 0 {
-				if err := page.SetDocumentContent(frameTree.Frame.ID, fmt.Sprintf(r.Content, r.ContentCss)).Do(ctx); err != nil {
-					return err
-				}
-			} else {
-				if err := page.SetDocumentContent(frameTree.Frame.ID, r.Content).Do(ctx); err != nil {
-					return err
-				}
+			if err := page.SetDocumentContent(frameTree.Frame.ID, documentContent(request)).Do(ctx); err != nil {
+				return err
 			}
 			wg.Wait()
 
 			return nil
 		}),
-		chromedp.Nodes("#"+r.WaitElementId, &nodes, chromedp.ByQuery, chromedp.AtLeast(0)),
-		chromedp.ActionFunc(r.pdfActions(res, request)),
 	}
-}
-
-func (r *html2PdfService) Wait(wg *sync.WaitGroup) chromedp.ActionFunc {
-	return func(ctx context.Context) error {
-		r.WgWait(wg)
-		return nil
+	if request.URL != "" {
+		load = chromedp.Tasks{r.loadURL(request)}
+	}
+	return chromedp.Tasks{
+		interceptor.listen(),
+		tracker.listen(),
+		load,
+		r.collectScripts(request, scripts),
+		r.scriptActions(dtos.ScriptAfterLoad, request, scripts),
+		r.waitActions(request, tracker),
+		r.scriptActions(dtos.ScriptBeforePrint, request, scripts),
+		finish,
+		interceptor.report(blocked),
 	}
-}
-
-func (r *html2PdfService) WgWait(wg *sync.WaitGroup) {
-	wg.Wait()
 }
 
 func (r *html2PdfService) pdfActions(res *[]byte, request dtos.HtmlRequest) chromedp.ActionFunc {
@@ -159,59 +279,99 @@ func (r *html2PdfService) pdfActions(res *[]byte, request dtos.HtmlRequest) chro
 }
 
 func (r *html2PdfService) DoPdfActions(res *[]byte, request dtos.HtmlRequest, ctx context.Context) error {
-	buf, err := doPrint(ctx, request)
+	request, err := r.fitPageHeight(ctx, request)
+	if err != nil {
+		return err
+	}
+	buf, err := doPrint(ctx, request, r.maxPdfSize)
 	if err != nil {
 		return err
 	}
+	if len(request.Inserts) > 0 {
+		if buf, err = insertPdfs(buf, request.Inserts, r.maxPdfSize); err != nil {
+			return err
+		}
+	}
 	*res = buf
 	return nil
 }
 
-func (r *html2PdfService) getFrameTree() chromedp.ActionFunc {
-	return func(ctx context.Context) error {
-		return r.DoGetFrameTree(ctx)
-	}
-}
-
-func (r *html2PdfService) DoGetFrameTree(ctx context.Context) error {
+func (r *html2PdfService) DoGetFrameTree(ctx context.Context, request dtos.HtmlRequest) error {
 	r.logger.Info("Getting Frame Tree")
 	frameTree, err := page.GetFrameTree().Do(ctx)
 	if err != nil {
 		return err
 	}
 	r.logger.Info("Getting Frame Tree finish")
-	if len(r.ContentCss) > 0 {
-		return page.SetDocumentContent(frameTree.Frame.ID, fmt.Sprintf(r.Content, r.ContentCss)).Do(ctx)
-	} else {
-		return page.SetDocumentContent(frameTree.Frame.ID, r.Content).Do(ctx)
+	return page.SetDocumentContent(frameTree.Frame.ID, documentContent(request)).Do(ctx)
+}
+
+// documentContent is the document set on the page for the request.
+func documentContent(request dtos.HtmlRequest) string {
+	content := request.Content
+	if styles := styleElements(request); styles != "" {
+		content = appendHead(content, styles)
+	}
+	if request.WaitFor != nil && request.WaitFor.ReadySignal != nil {
+		content = injectHead(content, readySignalScript)
+	}
+	if snippet := beforeLoadScripts(request); snippet != "" {
+		content = injectHead(content, snippet)
 	}
+	return content
+}
 
+// doPrint prints the page into memory, failing when the document exceeds
+// maxSize bytes.
+func doPrint(ctx context.Context, request dtos.HtmlRequest, maxSize int64) ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := printTo(ctx, request, &buf, maxSize); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
 }
 
-func doPrint(ctx context.Context, request dtos.HtmlRequest) ([]byte, error) {
-	buf, _, err := page.PrintToPDF().
-		WithDisplayHeaderFooter(request.DisplayHeaderFooter).
-		WithPrintBackground(true).
-		WithPreferCSSPageSize(request.PreferCSSPageSize).
-		WithScale(request.WithScale).
-		WithPaperWidth(request.PaperWidth).
-		WithPaperHeight(request.PaperHeight).
-		WithLandscape(request.Landscape).
-		WithMarginTop(request.MarginTop).
-		WithMarginRight(request.MarginRight).
-		WithMarginBottom(request.MarginBottom).
-		WithMarginLeft(request.MarginLeft).
+// printParams prints with the print options of the request, the defaults
+// standing for the ones it leaves out. An auto height request, its page
+// fitted to its content, prints its first page only.
+func printParams(request dtos.HtmlRequest) *page.PrintToPDFParams {
+	applyPrintOptions(&request, &printDefaults)
+	params := page.PrintToPDF()
+	if *request.AutoHeight {
+		params = params.WithPageRanges("1")
+	}
+	return params.
+		WithDisplayHeaderFooter(*request.DisplayHeaderFooter).
+		WithPrintBackground(*request.PrintBackground).
+		WithPreferCSSPageSize(*request.PreferCSSPageSize).
+		WithScale(*request.WithScale).
+		WithPaperWidth(float64(*request.PaperWidth)).
+		WithPaperHeight(float64(*request.PaperHeight)).
+		WithLandscape(*request.Landscape).
+		WithMarginTop(float64(*request.MarginTop)).
+		WithMarginRight(float64(*request.MarginRight)).
+		WithMarginBottom(float64(*request.MarginBottom)).
+		WithMarginLeft(float64(*request.MarginLeft)).
 		WithHeaderTemplate(request.HeaderTemplate).
-		WithFooterTemplate(request.FooterTemplate).
-		Do(ctx)
-	return buf, err
+		WithFooterTemplate(request.FooterTemplate)
 }
 
-func (r *html2PdfService) WriteHTML() http.Handler {
-	return http.HandlerFunc(r.DoHandler)
+func (r *html2PdfService) WriteHTML(request dtos.HtmlRequest) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, h *http.Request) {
+		r.DoHandler(w, h, request)
+	})
 }
 
-func (r *html2PdfService) DoHandler(w http.ResponseWriter, h *http.Request) {
+func (r *html2PdfService) DoHandler(w http.ResponseWriter, h *http.Request, request dtos.HtmlRequest) {
+	if name := strings.TrimPrefix(path.Clean(h.URL.Path), "/"); name != "" && len(request.Assets) > 0 {
+		asset, ok := request.Assets[name]
+		if !ok {
+			http.NotFound(w, h)
+			return
+		}
+		http.ServeContent(w, h, name, time.Time{}, bytes.NewReader(asset))
+		return
+	}
 	w.Header().Set("Content-Type", "text/css")
-	io.WriteString(w, strings.TrimSpace(r.ContentCss))
+	io.WriteString(w, strings.TrimSpace(request.ContentCss))
 }
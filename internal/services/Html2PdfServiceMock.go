@@ -72,7 +72,7 @@ func (r *Html2PdfServiceMock) PdfActions(res *[]byte, request dtos.HtmlRequest)
 	}
 }
 func (r *Html2PdfServiceMock) DoPdfActions(res *[]byte, request dtos.HtmlRequest, ctx context.Context) error {
-	buf, err := doPrint(ctx, request)
+	buf, err := doPrint(ctx, request, 0)
 	if err != nil {
 		return err
 	}
@@ -81,18 +81,19 @@ func (r *Html2PdfServiceMock) DoPdfActions(res *[]byte, request dtos.HtmlRequest
 }
 
 func doPrintMock(ctx context.Context, request dtos.HtmlRequest) ([]byte, error) {
+	applyPrintOptions(&request, &printDefaults)
 	buf, _, err := page.PrintToPDF().
-		WithDisplayHeaderFooter(request.DisplayHeaderFooter).
-		WithPrintBackground(request.PrintBackground).
-		WithPreferCSSPageSize(request.PreferCSSPageSize).
-		WithScale(request.WithScale).
-		WithPaperWidth(request.PaperWidth).
-		WithPaperHeight(request.PaperHeight).
-		WithLandscape(request.Landscape).
-		WithMarginTop(request.MarginTop).
-		WithMarginRight(request.MarginRight).
-		WithMarginBottom(request.MarginBottom).
-		WithMarginLeft(request.MarginLeft).
+		WithDisplayHeaderFooter(*request.DisplayHeaderFooter).
+		WithPrintBackground(*request.PrintBackground).
+		WithPreferCSSPageSize(*request.PreferCSSPageSize).
+		WithScale(*request.WithScale).
+		WithPaperWidth(float64(*request.PaperWidth)).
+		WithPaperHeight(float64(*request.PaperHeight)).
+		WithLandscape(*request.Landscape).
+		WithMarginTop(float64(*request.MarginTop)).
+		WithMarginRight(float64(*request.MarginRight)).
+		WithMarginBottom(float64(*request.MarginBottom)).
+		WithMarginLeft(float64(*request.MarginLeft)).
 		WithHeaderTemplate(request.HeaderTemplate).
 		WithFooterTemplate(request.FooterTemplate).
 		Do(ctx)
@@ -1,4 +1,147 @@
 package services
 
+import (
+	"context"
+	"net"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+)
+
 var DoPrint = doPrint
 var DoPrintMock = doPrintMock
+var InjectHead = injectHead
+var DocumentContent = documentContent
+var StyleElements = styleElements
+var UrlHeaders = urlHeaders
+var BeforeLoadScripts = beforeLoadScripts
+
+func NewHtml2PdfServiceWithScripts(dir string) *html2PdfService {
+	return &html2PdfService{scriptsDir: dir}
+}
+
+func (r *html2PdfService) ResolveScripts(scripts []dtos.Script) ([]dtos.Script, error) {
+	return r.resolveScripts(scripts)
+}
+
+func NewNetworkPolicy(c configs.Network) *networkPolicy {
+	return newNetworkPolicy(c, logger.NewFakeLogger())
+}
+
+func (p *networkPolicy) Check(rawURL string, own string, offline bool) string {
+	return p.check(context.Background(), rawURL, own, offline)
+}
+
+func (p *networkPolicy) Dial(address string) (net.Conn, error) {
+	return p.dialContext(&net.Dialer{})(context.Background(), "tcp", address)
+}
+
+func NewNetworkInterceptor(request dtos.HtmlRequest, own string, urlHosts ...string) *networkInterceptor {
+	return newNetworkInterceptor(NewNetworkPolicy(configs.GetConfig().Network), logger.NewFakeLogger(), own, request, urlHosts)
+}
+
+func (n *networkInterceptor) Check(rawURL string, resourceType network.ResourceType) string {
+	return n.check(context.Background(), rawURL, resourceType)
+}
+
+func NewHtml2PdfServiceWithURLHosts(hosts ...string) *html2PdfService {
+	return &html2PdfService{
+		networkPolicy: NewNetworkPolicy(configs.GetConfig().Network),
+		urlHosts:      hosts,
+	}
+}
+
+func (r *html2PdfService) CheckURL(request dtos.HtmlRequest) error {
+	return r.checkURL(context.Background(), request)
+}
+
+func NewJobServiceWith(ctx context.Context, html2PdfService interfaces.Html2PdfServiceInterface, cfg configs.Jobs, webhook configs.Webhook) interfaces.JobServiceInterface {
+	return NewJobServiceWithNetwork(ctx, html2PdfService, cfg, webhook, configs.Network{AllowSchemes: []string{"http"}})
+}
+
+func NewJobServiceWithNetwork(ctx context.Context, html2PdfService interfaces.Html2PdfServiceInterface, cfg configs.Jobs, webhook configs.Webhook, network configs.Network) interfaces.JobServiceInterface {
+	l := logger.NewFakeLogger()
+	return newJobService(ctx, l, html2PdfService, cfg, newWebhookNotifier(l, webhook, network))
+}
+
+var PdfPageCount = pdfPageCount
+
+func NewBatchServiceWith(html2PdfService interfaces.Html2PdfServiceInterface, cfg configs.Batch) interfaces.BatchServiceInterface {
+	return newBatchService(logger.NewFakeLogger(), html2PdfService, cfg, 0)
+}
+
+var BatchFilenames = batchFilenames
+
+var MailMergeFilename = filename
+
+var PageNumbering = pageNumbering
+
+func NewHtml2PdfServiceWithMaxAutoHeight(l logger.Logger, chromedpService *ChromedpService, max float64) interfaces.Html2PdfServiceInterface {
+	r := NewHtml2PdfService(l, chromedpService).(*html2PdfService)
+	r.maxAutoHeight = max
+	return r
+}
+
+func NewHtml2PdfServiceWithInserts(dir string) *html2PdfService {
+	return &html2PdfService{insertsDir: dir}
+}
+
+func (r *html2PdfService) ResolveInserts(request dtos.HtmlRequest) ([]dtos.PdfInsert, error) {
+	return r.resolveInserts(request)
+}
+
+var InsertPdfs = insertPdfs
+
+func NewHtml2PdfServiceWithTemplates(dir string) *html2PdfService {
+	return &html2PdfService{templates: newTemplateService(newFileTemplateStore(dir))}
+}
+
+func (r *html2PdfService) RenderTemplate(request dtos.HtmlRequest) (string, error) {
+	request, err := r.renderTemplate(request)
+	return request.Content, err
+}
+
+func (r *html2PdfService) ResolveTemplate(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	return r.renderTemplate(request)
+}
+
+func NewHtml2PdfServiceWithSchemas(dir string) *html2PdfService {
+	return &html2PdfService{schemasDir: dir}
+}
+
+func (r *html2PdfService) ValidateData(request dtos.HtmlRequest) error {
+	return r.validateData(request)
+}
+
+func (r *html2PdfService) ResolveData(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	return r.resolveData(request)
+}
+
+var NewTemplateStore = newTemplateStore
+
+func NewTemplateServiceWithDir(dir string) interfaces.TemplateServiceInterface {
+	return newTemplateService(newFileTemplateStore(dir))
+}
+
+var ResolvePrintOptions = resolvePrintOptions
+
+func NewProfileServiceWithDir(dir string) interfaces.ProfileServiceInterface {
+	return newProfileService(logger.NewFakeLogger(), dir)
+}
+
+func NewHtml2PdfServiceWithProfiles(profilesDir string, templatesDir string) *html2PdfService {
+	return &html2PdfService{
+		profiles:  newProfileService(logger.NewFakeLogger(), profilesDir),
+		templates: newTemplateService(newFileTemplateStore(templatesDir)),
+	}
+}
+
+func (r *html2PdfService) ResolveRequest(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	return r.resolveRequest(request)
+}
+
+var PrintParams = printParams
+var PageHeight = pageHeight
@@ -4,9 +4,9 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
-	"sync"
 	"testing"
 	"time"
 
@@ -23,6 +23,16 @@ const jsonFooter = `<div style="text-align: right;width: 297mm;font-size: 8px;op
 const jsonContent = `<html><body><h1>My First Heading</h1><p>My first paragraph.</p>/body></html>`
 const jsonContentCss = `* {            font-family: system-ui, system-ui, sans-serif;            font-size: 18pt;        }        body {            padding: 10pt;            margin: 15pt 20pt;        }        header, footer, h2, h5 {            text-align: center;            color: #979797;        }        h2 {            font-size: 19pt;        }        h5 {            font-size: 15pt;        }        h2, h5 {            margin: 0;        }        .tab {            tab-size: 4;        }        .assinatura {            margin: 20pt 0pt;            text-align: center;        }        table,        h2,        h5,        header,        footer,        #data,        #emitente {            border-spacing: 0pt;            width: 100%;        }        table {            margin-top: 30pt;            table-layout: fixed;            border: 1pt solid black;        }        th {            border-top: 1pt solid black;            border-left: 1pt dotted black;            border-right: 1pt dotted black;            border-bottom: 1pt dotted black;        }        td {            border: 1pt dotted black;        }        td {            vertical-align: bottom;        }        th, td {            padding: 5pt;            text-align: left;        }            td.small {                width: 5%;            }        .assin {            text-align: center;            margin-top: 20pt;            font-size: 18pt;        }        .nc {            text-align: center;            font-weight: bold;        }       ol {            padding: 0;            margin-left: 15pt;            margin-right: 15pt;            text-align: justify;        }        .center {            text-align: center;        }        .espaco {            margin-left: 15pt;        }        footer {            margin: 30pt 0;        }            footer div {                position: relative;            }            footer #page, footer #info {                position: absolute;            }            footer #page {                right: 8%;                border: 1pt solid #979797;                padding: 5pt 10pt;                top: -10pt;            }            footer #info {                left: 0;                bottom: 0;                font-size: 11pt;            }        p {            text-align: justify;        }`
 
+// skipWithoutBrowser skips tests that need to render when no browser can be
+// started on this machine.
+func skipWithoutBrowser(t *testing.T, err error) {
+	t.Helper()
+
+	if errors.Is(err, services.ErrPoolUnavailable) {
+		t.Skip("no browser available:", err)
+	}
+}
+
 func TestHtml2PdfService(t *testing.T) {
 
 	t.Run("NewHtml2PdfService", func(t *testing.T) {
@@ -51,6 +61,7 @@ func TestHtml2PdfService(t *testing.T) {
 		obj.ContentCss = jsonContentCss
 
 		pdfResponse, err := hs.HtmlToPdf(obj)
+		skipWithoutBrowser(t, err)
 
 		assert.NotNil(t, hs)
 		assert.NotNil(t, pdfResponse)
@@ -71,6 +82,7 @@ func TestHtml2PdfService(t *testing.T) {
 		obj.ContentCss = jsonContentCss
 
 		pdfResponse, err := hs.HtmlToPdf(obj)
+		skipWithoutBrowser(t, err)
 
 		assert.NotNil(t, hs)
 		assert.NotNil(t, pdfResponse)
@@ -93,6 +105,7 @@ func TestHtml2PdfService(t *testing.T) {
 		cdp.Context = nil
 
 		pdfResponse, err := hs.HtmlToPdf(obj)
+		skipWithoutBrowser(t, err)
 
 		assert.NotNil(t, hs)
 		assert.NotNil(t, pdfResponse)
@@ -113,16 +126,12 @@ func TestHtml2PdfService(t *testing.T) {
 		obj.ContentCss = jsonContentCss
 
 		pdfResponse, err := hs.HtmlToPdf(obj)
+		skipWithoutBrowser(t, err)
 		defer cdp.Cancelf()
 
 		assert.NotNil(t, hs)
 		assert.NotNil(t, pdfResponse)
 		assert.Nil(t, err)
-
-		handler := cdp.WriteHTML()
-
-		assert.NotNil(t, hs)
-		assert.NotNil(t, handler)
 	})
 
 	t.Run("TestDoPdfActions", func(t *testing.T) {
@@ -145,7 +154,7 @@ func TestHtml2PdfService(t *testing.T) {
 
 		var pdfBuffer []byte
 
-		ts := httptest.NewServer(hs.WriteHTML())
+		ts := httptest.NewServer(hs.WriteHTML(obj))
 		defer ts.Close()
 
 		chromedp.Run(taskCtx, hs.PdfGrabber(ts.URL, &pdfBuffer, obj))
@@ -170,6 +179,7 @@ func TestHtml2PdfService(t *testing.T) {
 		obj.Content = jsonContent
 		obj.ContentCss = jsonContentCss
 		pdfResponse, err := hs.HtmlToPdf(obj)
+		skipWithoutBrowser(t, err)
 
 		assert.NotNil(t, hs)
 		assert.NotNil(t, pdfResponse)
@@ -188,7 +198,7 @@ func TestHtml2PdfService(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 		assert.Equal(t, "404 page not found", w.Body.String())
 
-		hs.DoHandler(w, req)
+		hs.DoHandler(w, req, obj)
 		assert.NotNil(t, buf)
 		assert.Nil(t, err)
 
@@ -200,7 +210,7 @@ func TestHtml2PdfService(t *testing.T) {
 		obj.FooterTemplate = jsonFooter
 		obj.Content = jsonContent
 
-		buf, err := services.DoPrint(context.Background(), obj)
+		buf, err := services.DoPrint(context.Background(), obj, 0)
 
 		assert.Nil(t, buf)
 		assert.NotNil(t, err)
@@ -212,6 +222,7 @@ func TestHtml2PdfService(t *testing.T) {
 
 		cdp := services.NewChromedpService(context.Background(), logger)
 		cdp.RunChromeDp()
+		hs := services.NewHtml2PdfService(logger, cdp)
 
 		obj := dtos.HtmlRequest{}
 		obj.HeaderTemplate = jsonHeader
@@ -230,9 +241,22 @@ func TestHtml2PdfService(t *testing.T) {
 		assert.Equal(t, http.StatusNotFound, w.Code)
 		assert.Equal(t, "404 page not found", w.Body.String())
 
-		cdp.DoHandler(w, req)
-		assert.NotNil(t, w)
+		handler := hs.WriteHTML(obj)
+		assert.NotNil(t, handler)
+		ts := httptest.NewServer(handler)
+		defer ts.Close()
 
+		var heading string
+		err := cdp.Run(context.Background(), func(ctx context.Context) error {
+			return chromedp.Run(ctx,
+				chromedp.Navigate(ts.URL),
+				chromedp.Text(`h1`, &heading, chromedp.ByQuery),
+			)
+		})
+		skipWithoutBrowser(t, err)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "My First Heading", heading)
 	})
 
 	// t.Run("DoEventLoad", func(t *testing.T) {
@@ -290,16 +314,47 @@ func TestHtml2PdfService(t *testing.T) {
 		if cdp.Context == nil {
 			cdp.Context = context.Background()
 		}
-		hs.HtmlToPdf(obj)
-
-		var wg sync.WaitGroup
-		ch := make(chan int, 5)
-		wg.Add(1)
-		ch <- 1
+		pdfResponse, err := hs.HtmlToPdf(obj)
+		skipWithoutBrowser(t, err)
 
-		wait := hs.Wait(&wg)
-		hs.Wait(&wg)
-		assert.NotNil(t, wait)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, pdfResponse.Content)
 	})
 
 }
+
+func TestHtmlToImage(t *testing.T) {
+	logger := logger.NewFakeLogger()
+
+	cdp := services.NewChromedpService(context.Background(), logger)
+	cdp.RunChromeDp()
+	hs := services.NewHtml2PdfService(logger, cdp)
+
+	obj := dtos.ImageRequest{Format: dtos.ImageFormatWebp, Selector: "h1", Transparent: true}
+	obj.Content = jsonContent
+
+	imageResponse, err := hs.HtmlToImage(obj)
+	skipWithoutBrowser(t, err)
+
+	assert.Nil(t, err)
+	assert.NotEmpty(t, imageResponse.Content)
+	assert.Equal(t, "image/webp", imageResponse.ContentType)
+}
+
+func TestHtmlToPdfStream(t *testing.T) {
+	logger := logger.NewFakeLogger()
+
+	cdp := services.NewChromedpService(context.Background(), logger)
+	cdp.RunChromeDp()
+	hs := services.NewHtml2PdfService(logger, cdp)
+
+	obj := dtos.HtmlRequest{}
+	obj.Content = jsonContent
+
+	var buf bytes.Buffer
+	_, err := hs.HtmlToPdfStream(obj, &buf)
+	skipWithoutBrowser(t, err)
+
+	assert.Nil(t, err)
+	assert.True(t, bytes.HasPrefix(buf.Bytes(), []byte("%PDF-")))
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+var (
+	headTagExpr      = regexp.MustCompile(`(?i)<head(\s[^>]*)?>`)
+	headCloseTagExpr = regexp.MustCompile(`(?i)</head\s*>`)
+	htmlTagExpr      = regexp.MustCompile(`(?i)<html(\s[^>]*)?>`)
+	styleCloseExpr   = regexp.MustCompile(`(?i)</(style)`)
+)
+
+// injectHead inserts snippet at the start of the document head, creating
+// the head when the document has none.
+func injectHead(document string, snippet string) string {
+	if loc := headTagExpr.FindStringIndex(document); loc != nil {
+		return document[:loc[1]] + snippet + document[loc[1]:]
+	}
+	if loc := htmlTagExpr.FindStringIndex(document); loc != nil {
+		return document[:loc[1]] + "<head>" + snippet + "</head>" + document[loc[1]:]
+	}
+	return snippet + document
+}
+
+// appendHead inserts snippet at the end of the document head, so it comes
+// after the styles of the document itself.
+func appendHead(document string, snippet string) string {
+	if loc := headCloseTagExpr.FindStringIndex(document); loc != nil {
+		return document[:loc[0]] + snippet + document[loc[0]:]
+	}
+	return injectHead(document, snippet)
+}
+
+// styleElements builds the <style> elements of the request: the base
+// stylesheets first, then ContentCss, then each stylesheet in declaration
+// order.
+func styleElements(request dtos.HtmlRequest) string {
+	var b strings.Builder
+	for _, stylesheet := range request.BaseStylesheets {
+		writeStyle(&b, stylesheet.Name, stylesheet.Content)
+	}
+	if strings.TrimSpace(request.ContentCss) != "" {
+		writeStyle(&b, "", request.ContentCss)
+	}
+	for _, stylesheet := range request.Stylesheets {
+		writeStyle(&b, stylesheet.Name, stylesheet.Content)
+	}
+	return b.String()
+}
+
+// normalizeStylesheets returns copies of stylesheets with their line
+// endings turned into \n.
+func normalizeStylesheets(stylesheets []dtos.Stylesheet) []dtos.Stylesheet {
+	normalized := make([]dtos.Stylesheet, len(stylesheets))
+	for i, stylesheet := range stylesheets {
+		stylesheet.Content = strings.ReplaceAll(stylesheet.Content, "\r\n", "\n")
+		stylesheet.Content = strings.ReplaceAll(stylesheet.Content, "\r", "")
+		normalized[i] = stylesheet
+	}
+	return normalized
+}
+
+// writeStyle writes css as a <style> element. A closing </style> inside the
+// css is escaped so it cannot end the element early.
+func writeStyle(b *strings.Builder, name string, css string) {
+	css = styleCloseExpr.ReplaceAllString(css, `<\/$1`)
+	if name == "" {
+		fmt.Fprintf(b, "<style>%s</style>", css)
+		return
+	}
+	fmt.Fprintf(b, `<style data-name="%s">%s</style>`, html.EscapeString(name), css)
+}
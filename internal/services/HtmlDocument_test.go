@@ -0,0 +1,95 @@
+package services_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlDocument(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TestDocumentContentKeepsPercentSigns", func(t *testing.T) {
+		html := services.DocumentContent(dtos.HtmlRequest{
+			Content:    `<html><head><title>t</title></head><body><div style="width: 100%">50% off</div></body></html>`,
+			ContentCss: "body { width: 100%; }",
+		})
+
+		assert.Equal(t, `<html><head><title>t</title><style>body { width: 100%; }</style></head>`+
+			`<body><div style="width: 100%">50% off</div></body></html>`, html)
+	})
+
+	t.Run("TestDocumentContentWithoutHead", func(t *testing.T) {
+		html := services.DocumentContent(dtos.HtmlRequest{
+			Content:    "<p>10%</p>",
+			ContentCss: "p { color: red; }",
+		})
+
+		assert.Equal(t, "<style>p { color: red; }</style><p>10%</p>", html)
+	})
+
+	t.Run("TestStyleElementsNamedStylesheets", func(t *testing.T) {
+		html := services.StyleElements(dtos.HtmlRequest{
+			ContentCss: "a {}",
+			Stylesheets: []dtos.Stylesheet{
+				{Name: "base", Content: "b {}"},
+				{Name: `"print"`, Content: "c {}"},
+			},
+		})
+
+		assert.Equal(t, `<style>a {}</style><style data-name="base">b {}</style>`+
+			`<style data-name="&#34;print&#34;">c {}</style>`, html)
+	})
+
+	t.Run("TestStyleElementsEscapeClosingTag", func(t *testing.T) {
+		html := services.StyleElements(dtos.HtmlRequest{
+			Stylesheets: []dtos.Stylesheet{{Content: `a::after { content: "</STYLE><script>"; }`}},
+		})
+
+		assert.Equal(t, `<style>a::after { content: "<\/STYLE><script>"; }</style>`, html)
+	})
+}
+
+func TestDoHandlerAssets(t *testing.T) {
+	t.Parallel()
+
+	hs := services.NewHtml2PdfService(logger.NewFakeLogger(), nil)
+	obj := dtos.HtmlRequest{
+		ContentCss: "p {}",
+		Assets:     map[string][]byte{"img/logo.svg": []byte("<svg></svg>")},
+	}
+
+	t.Run("TestDoHandlerServesAsset", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/img/../img/logo.svg", nil)
+
+		hs.DoHandler(w, req, obj)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/svg+xml", w.Header().Get("Content-Type"))
+		assert.Equal(t, "<svg></svg>", w.Body.String())
+	})
+
+	t.Run("TestDoHandlerUnknownAsset", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/img/missing.png", nil)
+
+		hs.DoHandler(w, req, obj)
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("TestDoHandlerRoot", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+		hs.DoHandler(w, req, obj)
+
+		assert.Equal(t, "p {}", w.Body.String())
+	})
+}
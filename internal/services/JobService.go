@@ -0,0 +1,235 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
+)
+
+var (
+	// ErrJobNotFound reports an unknown or expired job.
+	ErrJobNotFound = errors.New("job not found")
+	// ErrJobNotDone reports a job whose result is not available, because
+	// it is still running or did not succeed.
+	ErrJobNotDone = errors.New("job not done")
+	// ErrQueueFull reports a job refused because too many are waiting.
+	ErrQueueFull = errors.New("job queue full")
+)
+
+type job struct {
+	dtos.Job
+	request       dtos.HtmlRequest
+	result        []byte
+	cancel        context.CancelFunc
+	callbackURL   string
+	correlationID string
+}
+
+// jobService renders the submitted requests on a bounded pool of workers,
+// keeping the jobs in memory until they expire. The jobs with a callback
+// URL have their outcome posted to it.
+type jobService struct {
+	ctx             context.Context
+	logger          logger.Logger
+	html2PdfService interfaces.Html2PdfServiceInterface
+	notifier        *webhookNotifier
+	timeout         time.Duration
+	ttl             time.Duration
+	queue           chan *job
+	jobs            map[string]*job
+	lock            sync.Mutex
+}
+
+func NewJobService(ctx context.Context, l logger.Logger, html2PdfService interfaces.Html2PdfServiceInterface) interfaces.JobServiceInterface {
+	cfg := configs.GetConfig()
+	return newJobService(ctx, l, html2PdfService, cfg.Jobs, newWebhookNotifier(l, cfg.Webhook, cfg.Network))
+}
+
+func newJobService(ctx context.Context, l logger.Logger, html2PdfService interfaces.Html2PdfServiceInterface, cfg configs.Jobs, notifier *webhookNotifier) *jobService {
+	s := &jobService{
+		ctx:             ctx,
+		logger:          l,
+		html2PdfService: html2PdfService,
+		notifier:        notifier,
+		timeout:         cfg.Timeout,
+		ttl:             cfg.TTL,
+		queue:           make(chan *job, cfg.QueueSize),
+		jobs:            map[string]*job{},
+	}
+	for i := 0; i < cfg.Workers; i++ {
+		go s.work(ctx)
+	}
+	go s.cleanup(ctx)
+	return s
+}
+
+// Submit queues the request. The correlation ID of the submitting request
+// is sent along the callback, when the request has one.
+func (s *jobService) Submit(request dtos.HtmlRequest, correlationID string) (dtos.Job, error) {
+	if request.CallbackURL != "" {
+		if err := s.notifier.check(s.ctx, request.CallbackURL); err != nil {
+			return dtos.Job{}, err
+		}
+	}
+	j := &job{
+		Job: dtos.Job{
+			ID:        uuid.NewString(),
+			Status:    dtos.JobQueued,
+			Filename:  request.Filename,
+			CreatedAt: time.Now(),
+		},
+		request:       request,
+		callbackURL:   request.CallbackURL,
+		correlationID: correlationID,
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	select {
+	case s.queue <- j:
+	default:
+		return dtos.Job{}, ErrQueueFull
+	}
+	s.jobs[j.ID] = j
+	return j.Job, nil
+}
+
+func (s *jobService) Get(id string) (dtos.Job, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return dtos.Job{}, ErrJobNotFound
+	}
+	return j.Job, nil
+}
+
+func (s *jobService) Result(id string) (dtos.Job, []byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return dtos.Job{}, nil, ErrJobNotFound
+	}
+	if j.Status != dtos.JobDone {
+		return j.Job, nil, ErrJobNotDone
+	}
+	return j.Job, j.result, nil
+}
+
+// Cancel stops a queued or rendering job. A finished job is deleted along
+// with its result.
+func (s *jobService) Cancel(id string) (dtos.Job, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return dtos.Job{}, ErrJobNotFound
+	}
+	switch j.Status {
+	case dtos.JobQueued:
+		s.finish(j, dtos.JobCanceled, "")
+	case dtos.JobRendering:
+		j.cancel()
+		s.finish(j, dtos.JobCanceled, "")
+	default:
+		delete(s.jobs, id)
+	}
+	return j.Job, nil
+}
+
+func (s *jobService) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case j := <-s.queue:
+			s.run(ctx, j)
+		}
+	}
+}
+
+func (s *jobService) run(ctx context.Context, j *job) {
+	s.lock.Lock()
+	if j.Status != dtos.JobQueued {
+		s.lock.Unlock()
+		return
+	}
+	renderCtx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	now := time.Now()
+	j.Status = dtos.JobRendering
+	j.StartedAt = &now
+	j.cancel = cancel
+	s.lock.Unlock()
+
+	response, err := s.html2PdfService.HtmlToPdfContext(renderCtx, j.request)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if j.Status != dtos.JobRendering {
+		return
+	}
+	switch {
+	case err != nil:
+		s.logger.Warn("job failed", zap.String("job", j.ID), zap.Error(err))
+		s.finish(j, dtos.JobFailed, err.Error())
+	case len(response.Content) == 0:
+		s.finish(j, dtos.JobFailed, "Timeout")
+	default:
+		j.result = response.Content
+		s.finish(j, dtos.JobDone, "")
+	}
+}
+
+// finish sets the final status of j and when it expires, then notifies
+// its callback URL. The caller holds the lock, so the event, which counts
+// the pages of the pdf, is built along its delivery from copies of j.
+func (s *jobService) finish(j *job, status string, reason string) {
+	now := time.Now()
+	expires := now.Add(s.ttl)
+	j.Status = status
+	j.Error = reason
+	j.FinishedAt = &now
+	j.ExpiresAt = &expires
+	j.request = dtos.HtmlRequest{}
+
+	if j.callbackURL != "" {
+		job, content, correlationID, callbackURL := j.Job, j.result, j.correlationID, j.callbackURL
+		go func() {
+			s.notifier.deliver(s.ctx, callbackURL, s.notifier.event(job, content, correlationID))
+		}()
+	}
+}
+
+// cleanup forgets the expired jobs.
+func (s *jobService) cleanup(ctx context.Context) {
+	interval := s.ttl
+	if interval <= 0 || interval > time.Minute {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.lock.Lock()
+			for id, j := range s.jobs {
+				if j.ExpiresAt != nil && now.After(*j.ExpiresAt) {
+					delete(s.jobs, id)
+				}
+			}
+			s.lock.Unlock()
+		}
+	}
+}
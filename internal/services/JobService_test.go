@@ -0,0 +1,128 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// renderStub renders through render, the other methods are not used.
+type renderStub struct {
+	interfaces.Html2PdfServiceInterface
+	render func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error)
+}
+
+func (s renderStub) HtmlToPdfContext(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+	return s.render(ctx, request)
+}
+
+func waitJob(t *testing.T, js interfaces.JobServiceInterface, id string, status string) dtos.Job {
+	var job dtos.Job
+	assert.Eventually(t, func() bool {
+		job, _ = js.Get(id)
+		return job.Status == status
+	}, 2*time.Second, 5*time.Millisecond)
+	return job
+}
+
+func TestJobService(t *testing.T) {
+	t.Parallel()
+
+	cfg := configs.Jobs{Workers: 2, QueueSize: 4, Timeout: time.Second, TTL: time.Hour}
+
+	t.Run("TestJobDone", func(t *testing.T) {
+		js := services.NewJobServiceWith(context.Background(), renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+			return dtos.PdfResponse{Content: []byte(request.Content)}, nil
+		}}, cfg, configs.Webhook{})
+
+		job, err := js.Submit(dtos.HtmlRequest{Content: "%PDF-", Filename: "report"}, "")
+		assert.NoError(t, err)
+		assert.Equal(t, dtos.JobQueued, job.Status)
+
+		job = waitJob(t, js, job.ID, dtos.JobDone)
+		assert.NotNil(t, job.StartedAt)
+		assert.NotNil(t, job.ExpiresAt)
+
+		job, content, err := js.Result(job.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "report", job.Filename)
+		assert.Equal(t, []byte("%PDF-"), content)
+	})
+
+	t.Run("TestJobFailed", func(t *testing.T) {
+		js := services.NewJobServiceWith(context.Background(), renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+			return dtos.PdfResponse{}, errors.New("boom")
+		}}, cfg, configs.Webhook{})
+
+		job, _ := js.Submit(dtos.HtmlRequest{}, "")
+		job = waitJob(t, js, job.ID, dtos.JobFailed)
+		assert.Equal(t, "boom", job.Error)
+
+		_, _, err := js.Result(job.ID)
+		assert.ErrorIs(t, err, services.ErrJobNotDone)
+	})
+
+	t.Run("TestJobCanceled", func(t *testing.T) {
+		canceled := make(chan error, 1)
+		js := services.NewJobServiceWith(context.Background(), renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+			<-ctx.Done()
+			canceled <- ctx.Err()
+			return dtos.PdfResponse{}, ctx.Err()
+		}}, cfg, configs.Webhook{})
+
+		job, _ := js.Submit(dtos.HtmlRequest{}, "")
+		waitJob(t, js, job.ID, dtos.JobRendering)
+
+		job, err := js.Cancel(job.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, dtos.JobCanceled, job.Status)
+		assert.ErrorIs(t, <-canceled, context.Canceled)
+
+		_, err = js.Cancel(job.ID)
+		assert.NoError(t, err)
+		_, err = js.Get(job.ID)
+		assert.ErrorIs(t, err, services.ErrJobNotFound)
+	})
+
+	t.Run("TestJobTimeout", func(t *testing.T) {
+		cfg := cfg
+		cfg.Timeout = 10 * time.Millisecond
+		js := services.NewJobServiceWith(context.Background(), renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+			<-ctx.Done()
+			return dtos.PdfResponse{}, ctx.Err()
+		}}, cfg, configs.Webhook{})
+
+		job, _ := js.Submit(dtos.HtmlRequest{}, "")
+		job = waitJob(t, js, job.ID, dtos.JobFailed)
+		assert.Equal(t, context.DeadlineExceeded.Error(), job.Error)
+	})
+
+	t.Run("TestQueueFull", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		js := services.NewJobServiceWith(ctx, renderStub{}, configs.Jobs{QueueSize: 1, TTL: time.Hour}, configs.Webhook{})
+
+		_, err := js.Submit(dtos.HtmlRequest{}, "")
+		assert.NoError(t, err)
+		_, err = js.Submit(dtos.HtmlRequest{}, "")
+		assert.ErrorIs(t, err, services.ErrQueueFull)
+	})
+
+	t.Run("TestJobNotFound", func(t *testing.T) {
+		js := services.NewJobServiceWith(context.Background(), renderStub{}, cfg, configs.Webhook{})
+
+		_, err := js.Get("missing")
+		assert.ErrorIs(t, err, services.ErrJobNotFound)
+		_, _, err = js.Result("missing")
+		assert.ErrorIs(t, err, services.ErrJobNotFound)
+		_, err = js.Cancel("missing")
+		assert.ErrorIs(t, err, services.ErrJobNotFound)
+	})
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"github.com/kolzxx/html2pdf/internal/spreadsheet"
+	"go.uber.org/zap"
+)
+
+// MailMerge renders the document of the request for each of the rows after
+// the first one, which names the columns, and writes to w the ZIP archive
+// of their pdfs or the pdf merging them, with a bookmark per row. A failed
+// row is only reported in its result and left out of the merged pdf, which
+// is not written at all when every row fails.
+func (s *batchService) MailMerge(ctx context.Context, request dtos.MailMergeRequest, rows []spreadsheet.Row, w io.Writer) ([]dtos.BatchItemResult, error) {
+	items, err := mailMergeItems(request, rows)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Check(items); err != nil {
+		return nil, err
+	}
+	results := make([]dtos.BatchItemResult, len(items))
+	for i, name := range batchFilenames(items) {
+		results[i] = dtos.BatchItemResult{Index: i, Row: rows[i+1].Number, Filename: name}
+	}
+	if request.Output == dtos.MailMergeZip {
+		return s.writeZip(ctx, items, results, w)
+	}
+	return s.writeMerged(ctx, items, results, w)
+}
+
+// mailMergeItems builds the request of each row, its Data holding the
+// values of the row by column along the Data of the mail merge request.
+func mailMergeItems(request dtos.MailMergeRequest, rows []spreadsheet.Row) ([]dtos.HtmlRequest, error) {
+	if len(rows) < 2 {
+		return nil, fmt.Errorf("%w: the data file has no row after its header", ErrInvalidRequest)
+	}
+	columns := make([]string, len(rows[0].Cells))
+	seen := map[string]bool{}
+	for i, cell := range rows[0].Cells {
+		columns[i] = strings.TrimSpace(cell)
+		if columns[i] == "" {
+			continue
+		}
+		if seen[columns[i]] {
+			return nil, fmt.Errorf("%w: column %q appears twice", ErrInvalidRequest, columns[i])
+		}
+		seen[columns[i]] = true
+	}
+	known := map[string]bool{}
+	for key := range request.Data {
+		known[key] = true
+	}
+	for column := range seen {
+		known[column] = true
+	}
+
+	sources := []string{request.FilenamePattern}
+	if request.Template == "" && request.TemplateName == "" {
+		sources = append(sources, request.Content)
+	}
+	for _, source := range sources {
+		for _, match := range placeholderExpr.FindAllStringSubmatch(source, -1) {
+			if field, _, _ := strings.Cut(match[1], "."); !known[field] {
+				return nil, fmt.Errorf("%w: no column %q for the {{%s}} placeholder", ErrInvalidRequest, field, match[1])
+			}
+		}
+	}
+
+	items := make([]dtos.HtmlRequest, len(rows)-1)
+	for i, row := range rows[1:] {
+		data := map[string]interface{}{}
+		for key, value := range request.Data {
+			data[key] = value
+		}
+		for j, column := range columns {
+			if column == "" {
+				continue
+			}
+			data[column] = ""
+			if j < len(row.Cells) {
+				data[column] = row.Cells[j]
+			}
+		}
+		item := request.HtmlRequest
+		item.Data = data
+		item.Filename = filename(request.FilenamePattern, data)
+		item.CallbackURL = ""
+		items[i] = item
+	}
+	return items, nil
+}
+
+var filenameReplacer = strings.NewReplacer("/", "-", "\\", "-", ":", "-", "*", "-", "?", "-", "\"", "-", "<", "-", ">", "-", "|", "-")
+
+// filename fills the placeholders of pattern with the data values, looked
+// up as in the content and kept out of the characters the file systems
+// refuse.
+func filename(pattern string, data map[string]interface{}) string {
+	return placeholderExpr.ReplaceAllStringFunc(pattern, func(match string) string {
+		text, _, _ := placeholderValue(data, placeholderExpr.FindStringSubmatch(match)[1])
+		return strings.Map(func(r rune) rune {
+			if r < ' ' {
+				return -1
+			}
+			return r
+		}, filenameReplacer.Replace(text))
+	})
+}
+
+// writeMerged renders the items and writes the pdf merging the ones that
+// succeed, recording their outcome in their results.
+func (s *batchService) writeMerged(ctx context.Context, items []dtos.HtmlRequest, results []dtos.BatchItemResult, w io.Writer) ([]dtos.BatchItemResult, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	done := make(chan batchItem)
+	go s.renderAll(ctx, items, done)
+
+	contents := make([][]byte, len(items))
+	for item := range done {
+		if item.err != nil {
+			results[item.index].Error = item.err.Error()
+			s.logger.Warn("Mail merge row failed", zap.Int("row", results[item.index].Row), zap.Error(item.err))
+			continue
+		}
+		contents[item.index] = item.content
+	}
+	if err := ctx.Err(); err != nil {
+		return results, err
+	}
+
+	parts := []pdf.Part{}
+	for i, content := range contents {
+		if content == nil {
+			continue
+		}
+		doc, err := pdf.Parse(content)
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		name := results[i].Filename
+		parts = append(parts, pdf.Part{Document: doc, Title: strings.TrimSuffix(name, path.Ext(name))})
+		results[i].Success = true
+		results[i].Size = len(content)
+	}
+	if len(parts) == 0 {
+		return results, nil
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Merge(&buf, parts...); err != nil {
+		return results, err
+	}
+	if s.maxPdfSize > 0 && int64(buf.Len()) > s.maxPdfSize {
+		return results, fmt.Errorf("%w: pdf exceeds %d bytes", ErrOutputTooLarge, s.maxPdfSize)
+	}
+	_, err := w.Write(buf.Bytes())
+	return results, err
+}
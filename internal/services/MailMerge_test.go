@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/kolzxx/html2pdf/internal/spreadsheet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMailMerge(t *testing.T) {
+	t.Parallel()
+
+	bs := services.NewBatchServiceWith(renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+		width, err := strconv.Atoi(request.Data["width"].(string))
+		if err != nil {
+			return dtos.PdfResponse{}, errors.New("no width")
+		}
+		return dtos.PdfResponse{Content: widthsPdf(width)}, nil
+	}}, configs.Batch{Concurrency: 2})
+
+	rows := []spreadsheet.Row{
+		{Number: 1, Cells: []string{"name", "width"}},
+		{Number: 2, Cells: []string{"Ann/Lee", "200"}},
+		{Number: 4, Cells: []string{"Bob", "wide"}},
+		{Number: 5, Cells: []string{"Cid", "300"}},
+	}
+	request := func(output string) dtos.MailMergeRequest {
+		return dtos.MailMergeRequest{
+			HtmlRequest:     dtos.HtmlRequest{Content: "<p>{{ name }}</p>"},
+			Output:          output,
+			FilenamePattern: "letter-{{name}}",
+		}
+	}
+
+	t.Run("TestMailMergePdf", func(t *testing.T) {
+		var out bytes.Buffer
+		results, err := bs.MailMerge(context.Background(), request(dtos.MailMergePdf), rows, &out)
+
+		assert.NoError(t, err)
+		if assert.Len(t, results, 3) {
+			assert.Equal(t, 2, results[0].Row)
+			assert.True(t, results[0].Success)
+			assert.Equal(t, "letter-Ann-Lee.pdf", results[0].Filename)
+			assert.Equal(t, 4, results[1].Row)
+			assert.False(t, results[1].Success)
+			assert.Equal(t, "no width", results[1].Error)
+			assert.True(t, results[2].Success)
+		}
+		assert.Equal(t, []int{200, 300}, pageWidths(out.Bytes()))
+		assert.Contains(t, out.String(), "(letter-Ann-Lee)")
+	})
+
+	t.Run("TestMailMergeZip", func(t *testing.T) {
+		var out bytes.Buffer
+		results, err := bs.MailMerge(context.Background(), request(dtos.MailMergeZip), rows, &out)
+
+		assert.NoError(t, err)
+		assert.Len(t, results, 3)
+		files := readArchive(t, out.Bytes())
+		assert.Contains(t, files, "letter-Ann-Lee.pdf")
+		assert.Contains(t, files, "letter-Cid.pdf")
+		assert.NotContains(t, files, "letter-Bob.pdf")
+		assert.Contains(t, files, services.BatchManifest)
+	})
+
+	t.Run("TestMailMergeAllFailed", func(t *testing.T) {
+		var out bytes.Buffer
+		results, err := bs.MailMerge(context.Background(), request(dtos.MailMergePdf), rows[:1:1], &out)
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+		assert.Nil(t, results)
+
+		results, err = bs.MailMerge(context.Background(), request(dtos.MailMergePdf), []spreadsheet.Row{rows[0], rows[2]}, &out)
+		assert.NoError(t, err)
+		assert.Len(t, results, 1)
+		assert.Zero(t, out.Len())
+	})
+
+	t.Run("TestMailMergeFilenameNestedData", func(t *testing.T) {
+		data := map[string]interface{}{
+			"customer": map[string]interface{}{"name": "Ann/Lee"},
+			"items":    []interface{}{"a", "b"},
+			"n":        3.0,
+		}
+
+		assert.Equal(t, "Ann-Lee-b-3-", services.MailMergeFilename("{{customer.name}}-{{ items.1 }}-{{n}}-{{customer}}", data))
+	})
+
+	t.Run("TestMailMergeInvalid", func(t *testing.T) {
+		for name, test := range map[string]struct {
+			content string
+			pattern string
+			header  []string
+		}{
+			"UnknownColumn":       {content: "{{ surname }}", header: []string{"name", "width"}},
+			"UnknownPatternField": {content: "{{ name }}", pattern: "{{id}}", header: []string{"name", "width"}},
+			"DuplicateColumn":     {content: "{{ name }}", header: []string{"name", "name"}},
+		} {
+			t.Run(name, func(t *testing.T) {
+				r := request(dtos.MailMergePdf)
+				r.Content = test.content
+				r.FilenamePattern = test.pattern
+				rows := []spreadsheet.Row{{Number: 1, Cells: test.header}, rows[1]}
+
+				_, err := bs.MailMerge(context.Background(), r, rows, &bytes.Buffer{})
+
+				assert.ErrorIs(t, err, services.ErrInvalidRequest)
+			})
+		}
+	})
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
+)
+
+// networkPolicy decides which requests a page may send. Deny rules win
+// over allow rules, and allow rules, when set, restrict requests to them.
+//
+// The addresses are checked on a resolution of their own, Chrome resolves
+// the host again to connect, so a host whose DNS answer changes in between
+// can still reach a denied address: the policy is best effort and does not
+// replace an egress firewall around the browsers. The webhook callbacks are
+// dialed with dialContext instead, on the addresses it checked.
+type networkPolicy struct {
+	allowHosts []string
+	denyHosts  []string
+	schemes    map[string]bool
+	allowNets  []netip.Prefix
+	denyNets   []netip.Prefix
+	offline    bool
+	resolver   *net.Resolver
+}
+
+func newNetworkPolicy(c configs.Network, l logger.Logger) *networkPolicy {
+	p := &networkPolicy{
+		allowHosts: lowerAll(c.AllowHosts),
+		denyHosts:  lowerAll(c.DenyHosts),
+		schemes:    map[string]bool{},
+		allowNets:  parsePrefixes(c.AllowCIDRs, l),
+		denyNets:   parsePrefixes(c.DenyCIDRs, l),
+		offline:    c.Offline,
+		resolver:   net.DefaultResolver,
+	}
+	for _, scheme := range c.AllowSchemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+	return p
+}
+
+func lowerAll(items []string) []string {
+	lowered := make([]string, len(items))
+	for i, item := range items {
+		lowered[i] = strings.ToLower(item)
+	}
+	return lowered
+}
+
+func parsePrefixes(cidrs []string, l logger.Logger) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			l.Error("Ignoring invalid CIDR", zap.String("cidr", cidr), zap.Error(err))
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// check returns why the page may not request rawURL, or an empty string
+// when it may. Requests to own, the host of the request asset server, are
+// always allowed; offline forbids every other request. WebSockets escape
+// the request interception and are blocked by the interceptor instead.
+func (p *networkPolicy) check(ctx context.Context, rawURL string, own string, offline bool) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid url"
+	}
+	if u.Scheme == "http" && u.Host == own {
+		return ""
+	}
+	if offline || p.offline {
+		return "offline"
+	}
+	scheme := strings.ToLower(u.Scheme)
+	if !p.schemes[scheme] {
+		return fmt.Sprintf("scheme %s not allowed", scheme)
+	}
+	if scheme == "data" || scheme == "blob" {
+		return ""
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if matchHost(p.denyHosts, host) {
+		return fmt.Sprintf("host %s denied", host)
+	}
+	if len(p.allowHosts) > 0 && !matchHost(p.allowHosts, host) {
+		return fmt.Sprintf("host %s not allowed", host)
+	}
+
+	addrs, err := p.lookup(ctx, host)
+	if err != nil {
+		return fmt.Sprintf("host %s not resolved", host)
+	}
+	return p.checkAddrs(addrs)
+}
+
+// checkAddrs returns why the addresses of a host may not be requested, or
+// an empty string when they may.
+func (p *networkPolicy) checkAddrs(addrs []netip.Addr) string {
+	for _, addr := range addrs {
+		if containsAddr(p.denyNets, addr) {
+			return fmt.Sprintf("address %s denied", addr)
+		}
+		if len(p.allowNets) > 0 && !containsAddr(p.allowNets, addr) {
+			return fmt.Sprintf("address %s not allowed", addr)
+		}
+	}
+	return ""
+}
+
+// dialContext dials with dialer one of the addresses the host resolves to,
+// once the policy allowed them, so that the host cannot resolve to a
+// denied address between the check and the connection.
+func (p *networkPolicy) dialContext(dialer *net.Dialer) func(ctx context.Context, network string, address string) (net.Conn, error) {
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+		addrs, err := p.lookup(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+		if reason := p.checkAddrs(addrs); reason != "" {
+			return nil, fmt.Errorf("dial %s blocked: %s", address, reason)
+		}
+		for _, addr := range addrs {
+			var conn net.Conn
+			if conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(addr.String(), port)); err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+}
+
+func (p *networkPolicy) lookup(ctx context.Context, host string) ([]netip.Addr, error) {
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr.Unmap()}, nil
+	}
+	ips, err := p.resolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for i, ip := range ips {
+		ips[i] = ip.Unmap()
+	}
+	return ips, nil
+}
+
+// matchHost tells whether host is one of patterns. A pattern starting
+// with "*." matches the subdomains of the rest of it.
+func matchHost(patterns []string, host string) bool {
+	for _, pattern := range patterns {
+		if suffix, ok := strings.CutPrefix(pattern, "*"); ok && strings.HasSuffix(host, suffix) {
+			return true
+		}
+		if pattern == host {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// networkInterceptor pauses every request of a page to enforce the policy,
+// keeping the ones it blocked.
+type networkInterceptor struct {
+	policy   *networkPolicy
+	logger   logger.Logger
+	own      string
+	offline  bool
+	origin   string
+	headers  map[string]string
+	urlHosts []string
+	lock     sync.Mutex
+	blocked  []dtos.BlockedRequest
+}
+
+// newNetworkInterceptor builds the interceptor of a render. The extra
+// headers of a URL source are added to the requests sent to its origin,
+// and its documents, redirects and later navigations included, must be on
+// one of urlHosts.
+func newNetworkInterceptor(policy *networkPolicy, l logger.Logger, own string, request dtos.HtmlRequest, urlHosts []string) *networkInterceptor {
+	return &networkInterceptor{
+		policy:   policy,
+		logger:   l,
+		own:      own,
+		offline:  request.Offline,
+		origin:   urlOrigin(request.URL),
+		headers:  urlHeaders(request),
+		urlHosts: urlHosts,
+	}
+}
+
+// webSocketPatterns are blocked on every page: the Fetch domain does not
+// pause WebSocket handshakes, so the policy could not check them.
+var webSocketPatterns = []string{"ws://*", "wss://*"}
+
+// listen enables the interception on the page. The paused requests are
+// answered outside the event loop, which must not block on commands.
+func (n *networkInterceptor) listen() chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			switch ev := ev.(type) {
+			case *fetch.EventRequestPaused:
+				go n.handle(ctx, ev)
+			case *network.EventWebSocketCreated:
+				n.block(ev.URL, "websockets not allowed")
+			}
+		})
+		if err := network.Enable().Do(ctx); err != nil {
+			return err
+		}
+		if err := network.SetBlockedURLS(webSocketPatterns).Do(ctx); err != nil {
+			return err
+		}
+		return fetch.Enable().Do(ctx)
+	}
+}
+
+func (n *networkInterceptor) handle(ctx context.Context, ev *fetch.EventRequestPaused) {
+	ctx = cdp.WithExecutor(ctx, chromedp.FromContext(ctx).Target)
+
+	reason := n.check(ctx, ev.Request.URL, ev.ResourceType)
+	if reason == "" {
+		if err := n.continueRequest(ev).Do(ctx); err != nil && ctx.Err() == nil {
+			n.logger.Error("Error continuing request", zap.Error(err))
+		}
+		return
+	}
+
+	n.block(ev.Request.URL, reason)
+	if err := fetch.FailRequest(ev.RequestID, network.ErrorReasonBlockedByClient).Do(ctx); err != nil && ctx.Err() == nil {
+		n.logger.Error("Error blocking request", zap.Error(err))
+	}
+}
+
+// check returns why the page may not send a request of resourceType to
+// rawURL, or an empty string when it may.
+func (n *networkInterceptor) check(ctx context.Context, rawURL string, resourceType network.ResourceType) string {
+	if reason := n.policy.check(ctx, rawURL, n.own, n.offline); reason != "" {
+		return reason
+	}
+	if n.origin == "" || resourceType != network.ResourceTypeDocument {
+		return ""
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "invalid url"
+	}
+	if u.Scheme == "http" && u.Host == n.own {
+		return ""
+	}
+	if host := strings.ToLower(u.Hostname()); !matchHost(n.urlHosts, host) {
+		return fmt.Sprintf("url host %s not allowed", host)
+	}
+	return ""
+}
+
+func (n *networkInterceptor) block(url string, reason string) {
+	n.lock.Lock()
+	n.blocked = append(n.blocked, dtos.BlockedRequest{URL: url, Reason: reason})
+	n.lock.Unlock()
+}
+
+func (n *networkInterceptor) continueRequest(ev *fetch.EventRequestPaused) *fetch.ContinueRequestParams {
+	continueRequest := fetch.ContinueRequest(ev.RequestID)
+	if len(n.headers) == 0 || urlOrigin(ev.Request.URL) != n.origin {
+		return continueRequest
+	}
+	var headers []*fetch.HeaderEntry
+	for name, value := range ev.Request.Headers {
+		if _, ok := n.headers[http.CanonicalHeaderKey(name)]; !ok {
+			headers = append(headers, &fetch.HeaderEntry{Name: name, Value: fmt.Sprint(value)})
+		}
+	}
+	for name, value := range n.headers {
+		headers = append(headers, &fetch.HeaderEntry{Name: name, Value: value})
+	}
+	return continueRequest.WithHeaders(headers)
+}
+
+// report copies the requests blocked so far into blocked.
+func (n *networkInterceptor) report(blocked *[]dtos.BlockedRequest) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		n.lock.Lock()
+		defer n.lock.Unlock()
+		*blocked = append([]dtos.BlockedRequest(nil), n.blocked...)
+		return nil
+	}
+}
@@ -0,0 +1,72 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := services.NewNetworkPolicy(configs.GetConfig().Network)
+	const own = "127.0.0.1:4321"
+
+	t.Run("TestOwnAssetServerAllowed", func(t *testing.T) {
+		assert.Empty(t, policy.Check("http://127.0.0.1:4321/img/logo.png", own, true))
+	})
+
+	t.Run("TestOfflineBlocksOtherHosts", func(t *testing.T) {
+		assert.Equal(t, "offline", policy.Check("https://93.184.216.34/", own, true))
+	})
+
+	t.Run("TestMetadataAddressDenied", func(t *testing.T) {
+		assert.Equal(t, "address 169.254.169.254 denied", policy.Check("http://169.254.169.254/latest/meta-data/", own, false))
+		assert.Equal(t, "address fe80::1 denied", policy.Check("http://[fe80::1]/", own, false))
+	})
+
+	t.Run("TestLoopbackAndPrivateAddressesDenied", func(t *testing.T) {
+		assert.Equal(t, "address 127.0.0.1 denied", policy.Check("http://127.0.0.1:9222/json/version", own, false))
+		assert.Equal(t, "address ::1 denied", policy.Check("http://[::1]:8080/", own, false))
+		assert.Equal(t, "address 10.0.0.5 denied", policy.Check("http://10.0.0.5/", own, false))
+		assert.Equal(t, "address 192.168.1.1 denied", policy.Check("http://192.168.1.1/", own, false))
+		assert.Equal(t, "address fd12::1 denied", policy.Check("http://[fd12::1]/", own, false))
+	})
+
+	t.Run("TestMetadataHostDenied", func(t *testing.T) {
+		assert.Equal(t, "host metadata.google.internal denied", policy.Check("http://METADATA.google.internal/", own, false))
+	})
+
+	t.Run("TestDialDeniedAddress", func(t *testing.T) {
+		_, err := policy.Dial("localhost:9222")
+
+		assert.ErrorContains(t, err, "address 127.0.0.1 denied")
+	})
+
+	t.Run("TestSchemeNotAllowed", func(t *testing.T) {
+		assert.Equal(t, "scheme file not allowed", policy.Check("file:///etc/passwd", own, false))
+	})
+
+	t.Run("TestDataUrlAllowed", func(t *testing.T) {
+		assert.Empty(t, policy.Check("data:image/png;base64,AAAA", own, false))
+	})
+
+	t.Run("TestPublicAddressAllowed", func(t *testing.T) {
+		assert.Empty(t, policy.Check("https://93.184.216.34/logo.png", own, false))
+	})
+
+	t.Run("TestAllowLists", func(t *testing.T) {
+		policy := services.NewNetworkPolicy(configs.Network{
+			AllowHosts:   []string{"*.example.com", "10.0.0.1", "10.1.0.1"},
+			AllowSchemes: []string{"https"},
+			AllowCIDRs:   []string{"10.0.0.0/24", "invalid"},
+		})
+
+		assert.Empty(t, policy.Check("https://10.0.0.1/", own, false))
+		assert.Equal(t, "address 10.1.0.1 not allowed", policy.Check("https://10.1.0.1/", own, false))
+		assert.Equal(t, "host 10.0.0.2 not allowed", policy.Check("https://10.0.0.2/", own, false))
+		assert.Equal(t, "scheme http not allowed", policy.Check("http://10.0.0.1/", own, false))
+	})
+}
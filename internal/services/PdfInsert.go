@@ -0,0 +1,106 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/pdf"
+)
+
+// resolveInserts loads the pdfs the inserts of the request refer to, from
+// the inserts directory or the uploaded assets, and checks that they can
+// be read.
+func (r *html2PdfService) resolveInserts(request dtos.HtmlRequest) ([]dtos.PdfInsert, error) {
+	if len(request.Inserts) == 0 {
+		return nil, nil
+	}
+	resolved := make([]dtos.PdfInsert, len(request.Inserts))
+	for i, insert := range request.Inserts {
+		resolved[i] = insert
+		if insert.File != "" {
+			name := strings.TrimPrefix(path.Clean("/"+strings.ReplaceAll(insert.File, "\\", "/")), "/")
+			content, ok := request.Assets[name]
+			if !ok {
+				return nil, fmt.Errorf("%w: insert %d refers to no uploaded file %q", ErrInvalidRequest, i, insert.File)
+			}
+			resolved[i].Content = content
+		} else {
+			if !scriptNameExpr.MatchString(insert.Name) {
+				return nil, fmt.Errorf("%w: insert %d has no valid name", ErrInvalidRequest, i)
+			}
+			content, err := os.ReadFile(filepath.Join(r.insertsDir, insert.Name+".pdf"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: unknown insert %q", ErrInvalidRequest, insert.Name)
+			}
+			resolved[i].Content = content
+		}
+		if _, err := pdf.Parse(resolved[i].Content); err != nil {
+			return nil, fmt.Errorf("%w: insert %d: %s", ErrInvalidRequest, i, err)
+		}
+	}
+	return resolved, nil
+}
+
+// insertPdfs places the inserts into the rendered pdf content, leaving out
+// their JavaScript. The inserts at a same position keep their order.
+func insertPdfs(content []byte, inserts []dtos.PdfInsert, maxSize int64) ([]byte, error) {
+	doc, err := pdf.Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	pages := doc.NumPages()
+
+	// at lists the inserts by the number of rendered pages before them
+	at := map[int][]pdf.Part{}
+	for i, insert := range inserts {
+		if insert.Page > pages {
+			return nil, fmt.Errorf("%w: insert %d is placed at page %d of a %d pages document", ErrInvalidRequest, i, insert.Page, pages)
+		}
+		position := pages
+		switch {
+		case insert.Page > 0 && insert.Position == dtos.InsertBefore:
+			position = insert.Page - 1
+		case insert.Page > 0:
+			position = insert.Page
+		case insert.Position == dtos.InsertBefore:
+			position = 0
+		}
+		inserted, err := pdf.Parse(insert.Content)
+		if err != nil {
+			return nil, fmt.Errorf("%w: insert %d: %s", ErrInvalidRequest, i, err)
+		}
+		at[position] = append(at[position], pdf.Part{Document: inserted})
+	}
+
+	var parts []pdf.Part
+	var rendered []int
+	for n := 0; n <= pages; n++ {
+		if len(at[n]) > 0 {
+			if len(rendered) > 0 {
+				parts = append(parts, pdf.Part{Document: doc, Pages: rendered})
+				rendered = nil
+			}
+			parts = append(parts, at[n]...)
+		}
+		if n < pages {
+			rendered = append(rendered, n)
+		}
+	}
+	if len(rendered) > 0 {
+		parts = append(parts, pdf.Part{Document: doc, Pages: rendered})
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Merge(&buf, parts...); err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && int64(buf.Len()) > maxSize {
+		return nil, fmt.Errorf("%w: pdf exceeds %d bytes", ErrOutputTooLarge, maxSize)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,120 @@
+package services_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+// widthsPdf is a pdf with a page of each width, telling them apart once
+// merged.
+func widthsPdf(widths ...int) []byte {
+	objects := []string{"<< /Type /Catalog /Pages 2 0 R /OpenAction << /S /JavaScript /JS (app.alert(1)) >> >>", ""}
+	var kids []string
+	for _, width := range widths {
+		objects = append(objects, fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d 100] >>", width))
+		kids = append(kids, fmt.Sprintf("%d 0 R", len(objects)))
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids))
+
+	var b bytes.Buffer
+	b.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, o := range objects {
+		offsets[i] = b.Len()
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", i+1, o)
+	}
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return b.Bytes()
+}
+
+// pageWidths lists the page widths of a pdf written by widthsPdf, in order.
+func pageWidths(content []byte) []int {
+	var widths []int
+	for _, part := range strings.Split(string(content), "/MediaBox [0 0 ")[1:] {
+		var width int
+		fmt.Sscanf(part, "%d", &width)
+		widths = append(widths, width)
+	}
+	return widths
+}
+
+func TestResolveInserts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "terms.pdf"), widthsPdf(200), 0o644)
+	os.WriteFile(filepath.Join(dir, "broken.pdf"), []byte("%PDF-1.4 nothing"), 0o644)
+	hs := services.NewHtml2PdfServiceWithInserts(dir)
+
+	t.Run("TestResolveInserts", func(t *testing.T) {
+		inserts, err := hs.ResolveInserts(dtos.HtmlRequest{
+			Inserts: []dtos.PdfInsert{{Name: "terms"}, {File: "./annex/leaflet.pdf"}},
+			Assets:  map[string][]byte{"annex/leaflet.pdf": widthsPdf(300)},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{200}, pageWidths(inserts[0].Content))
+		assert.Equal(t, []int{300}, pageWidths(inserts[1].Content))
+	})
+
+	t.Run("TestResolveInsertsInvalid", func(t *testing.T) {
+		for name, insert := range map[string]dtos.PdfInsert{
+			"Unknown":   {Name: "missing"},
+			"Traversal": {Name: "../terms"},
+			"Malformed": {Name: "broken"},
+			"NoUpload":  {File: "leaflet.pdf"},
+		} {
+			_, err := hs.ResolveInserts(dtos.HtmlRequest{Inserts: []dtos.PdfInsert{insert}})
+
+			assert.ErrorIs(t, err, services.ErrInvalidRequest, name)
+		}
+	})
+}
+
+func TestInsertPdfs(t *testing.T) {
+	t.Parallel()
+
+	rendered := widthsPdf(101, 102)
+
+	t.Run("TestInsertPdfsPositions", func(t *testing.T) {
+		content, err := services.InsertPdfs(rendered, []dtos.PdfInsert{
+			{Content: widthsPdf(201), Position: dtos.InsertBefore},
+			{Content: widthsPdf(202), Page: 1},
+			{Content: widthsPdf(203, 204)},
+			{Content: widthsPdf(205), Page: 1, Position: dtos.InsertBefore},
+		}, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []int{201, 205, 101, 202, 102, 203, 204}, pageWidths(content))
+		assert.NotContains(t, string(content), "JavaScript")
+		doc, err := pdf.Parse(content)
+		assert.NoError(t, err)
+		assert.Equal(t, 7, doc.NumPages())
+	})
+
+	t.Run("TestInsertPdfsPageOutOfRange", func(t *testing.T) {
+		_, err := services.InsertPdfs(rendered, []dtos.PdfInsert{{Content: widthsPdf(201), Page: 3}}, 0)
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestInsertPdfsTooLarge", func(t *testing.T) {
+		_, err := services.InsertPdfs(rendered, []dtos.PdfInsert{{Content: widthsPdf(201)}}, 100)
+
+		assert.ErrorIs(t, err, services.ErrOutputTooLarge)
+	})
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"go.uber.org/zap"
+)
+
+// pagePaddingScript adds blank pages before and after the body content, so
+// that a section printed out of them numbers its pages as part of a larger
+// document.
+const pagePaddingScript = `(function(before, after) {
+	function blank(side) {
+		var div = document.createElement('div');
+		div.style.cssText = 'height:1px;margin:0;padding:0;break-' + side + ':page';
+		return div;
+	}
+	for (var i = 0; i < before; i++) document.body.insertBefore(blank('after'), document.body.firstChild);
+	for (var i = 0; i < after; i++) document.body.appendChild(blank('before'));
+})(%d, %d)`
+
+// HtmlToPdfMerge renders the sections of the request one after the other
+// and merges them into a single pdf, with a bookmark to each section. The
+// sections whose header or footer shows page numbers are rendered a second
+// time, padded with the pages of the other sections, for their numbers to
+// run through the whole document.
+func (r *html2PdfService) HtmlToPdfMerge(ctx context.Context, request dtos.MergeRequest) (dtos.PdfResponse, error) {
+	var response dtos.PdfResponse
+	docs := make([]*pdf.Document, len(request.Sections))
+	sections := make([]dtos.HtmlRequest, len(request.Sections))
+	total := 0
+	for i, section := range request.Sections {
+		// a section is resolved first, for the header and footer of its
+		// profile, its template or the defaults to count in its page
+		// numbering
+		var err error
+		if sections[i], err = r.resolveRequest(section.HtmlRequest); err != nil {
+			return dtos.PdfResponse{}, fmt.Errorf("section %d: %w", i+1, err)
+		}
+		resp, err := r.HtmlToPdfContext(ctx, sections[i])
+		if err != nil {
+			return dtos.PdfResponse{}, fmt.Errorf("section %d: %w", i+1, err)
+		}
+		if len(resp.Content) == 0 {
+			return dtos.PdfResponse{}, fmt.Errorf("section %d: no pdf rendered", i+1)
+		}
+		if docs[i], err = pdf.Parse(resp.Content); err != nil {
+			return dtos.PdfResponse{}, fmt.Errorf("section %d: %w", i+1, err)
+		}
+		total += docs[i].NumPages()
+		response.Scripts = append(response.Scripts, resp.Scripts...)
+		response.BlockedRequests = append(response.BlockedRequests, resp.BlockedRequests...)
+	}
+
+	offset := 0
+	for i, section := range sections {
+		pages := docs[i].NumPages()
+		number, count := pageNumbering(section)
+		// the inserts of a section and its auto height, measured on its own
+		// content, keep it from being printed out of a padded document
+		if len(section.Inserts) == 0 && !*section.AutoHeight && ((number && offset > 0) || (count && total > pages)) {
+			content, err := r.renderPadded(ctx, section, offset, total-offset-pages, pages)
+			if err != nil {
+				return dtos.PdfResponse{}, fmt.Errorf("section %d: %w", i+1, err)
+			}
+			doc, err := pdf.Parse(content)
+			if err == nil && doc.NumPages() == pages {
+				docs[i] = doc
+			} else {
+				r.logger.Warn("Html2PdfMerge - Section kept with its own page numbers", zap.Int("section", i+1), zap.Error(err))
+			}
+		}
+		offset += pages
+	}
+
+	parts := make([]pdf.Part, len(docs))
+	for i, section := range request.Sections {
+		title := section.Title
+		if title == "" {
+			title = fmt.Sprintf("Section %d", i+1)
+		}
+		parts[i] = pdf.Part{Document: docs[i], Title: title}
+	}
+	var buf bytes.Buffer
+	if err := pdf.Merge(&buf, parts...); err != nil {
+		return dtos.PdfResponse{}, err
+	}
+	if r.maxPdfSize > 0 && int64(buf.Len()) > r.maxPdfSize {
+		return dtos.PdfResponse{}, fmt.Errorf("%w: pdf exceeds %d bytes", ErrOutputTooLarge, r.maxPdfSize)
+	}
+	response.Content = buf.Bytes()
+	return response, nil
+}
+
+// pageNumbering tells whether the header or footer of the request show the
+// page number and the page count. The default templates of the browser
+// show both.
+func pageNumbering(request dtos.HtmlRequest) (number bool, count bool) {
+	if request.DisplayHeaderFooter == nil || !*request.DisplayHeaderFooter {
+		return false, false
+	}
+	if request.HeaderTemplate == "" || request.FooterTemplate == "" {
+		return true, true
+	}
+	templates := request.HeaderTemplate + request.FooterTemplate
+	return strings.Contains(templates, "pageNumber"), strings.Contains(templates, "totalPages")
+}
+
+// renderPadded renders the request with before blank pages ahead of its
+// content and after ones behind it, and prints the pages of the content
+// only.
+func (r *html2PdfService) renderPadded(ctx context.Context, request dtos.HtmlRequest, before int, after int, pages int) ([]byte, error) {
+	var content []byte
+	err := r.render(ctx, request, func(url string, request dtos.HtmlRequest) chromedp.Tasks {
+		var scripts []dtos.ScriptResult
+		var blocked []dtos.BlockedRequest
+		return chromedp.Tasks{
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				content = nil
+				return nil
+			}),
+			r.pageTasks(url, request, &scripts, &blocked, chromedp.ActionFunc(func(ctx context.Context) error {
+				if err := chromedp.Evaluate(fmt.Sprintf(pagePaddingScript, before, after), nil).Do(ctx); err != nil {
+					return err
+				}
+				var buf bytes.Buffer
+				params := printParams(request).WithPageRanges(fmt.Sprintf("%d-%d", before+1, before+pages))
+				if _, err := printStream(ctx, params, &buf, r.maxPdfSize); err != nil {
+					return err
+				}
+				content = buf.Bytes()
+				return nil
+			})),
+		}
+	}, func() bool {
+		return len(content) > 0
+	})
+	return content, err
+}
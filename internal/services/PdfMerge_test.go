@@ -0,0 +1,63 @@
+package services_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlToPdfMerge(t *testing.T) {
+	logger := logger.NewFakeLogger()
+
+	cdp := services.NewChromedpService(context.Background(), logger)
+	cdp.RunChromeDp()
+	hs := services.NewHtml2PdfService(logger, cdp)
+
+	cover := dtos.MergeSection{Title: "Cover"}
+	cover.Content = jsonContent
+	body := dtos.MergeSection{}
+	body.Content = jsonContent
+	yes := true
+	body.Landscape = &yes
+	body.DisplayHeaderFooter = &yes
+	body.FooterTemplate = `<span class="pageNumber"></span>/<span class="totalPages"></span>`
+	body.HeaderTemplate = `<span></span>`
+
+	response, err := hs.HtmlToPdfMerge(context.Background(), dtos.MergeRequest{Sections: []dtos.MergeSection{cover, body}})
+	skipWithoutBrowser(t, err)
+
+	assert.Nil(t, err)
+	doc, err := pdf.Parse(response.Content)
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, doc.NumPages(), 2)
+	assert.Contains(t, string(response.Content), "/Title (Cover)")
+	assert.Contains(t, string(response.Content), "/Title (Section 2)")
+}
+
+func TestPageNumbering(t *testing.T) {
+	t.Parallel()
+
+	yes := true
+	display := dtos.PrintOptions{DisplayHeaderFooter: &yes}
+	for name, tc := range map[string]struct {
+		request       dtos.HtmlRequest
+		number, count bool
+	}{
+		"NoHeaderFooter":   {dtos.HtmlRequest{}, false, false},
+		"DefaultTemplates": {dtos.HtmlRequest{PrintOptions: display}, true, true},
+		"PageNumber":       {dtos.HtmlRequest{PrintOptions: display, HeaderTemplate: "<span></span>", FooterTemplate: `<span class="pageNumber"></span>`}, true, false},
+		"TotalPages":       {dtos.HtmlRequest{PrintOptions: display, HeaderTemplate: `<span class="totalPages"></span>`, FooterTemplate: "<span></span>"}, false, true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			number, count := services.PageNumbering(tc.request)
+
+			assert.Equal(t, tc.number, number)
+			assert.Equal(t, tc.count, count)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/chromedp/cdproto/cdp"
+	cdpio "github.com/chromedp/cdproto/io"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// ErrOutputTooLarge reports a document larger than the configured maximum.
+var ErrOutputTooLarge = errors.New("output too large")
+
+// printChunkSize is the size of the chunks read from the pdf stream.
+const printChunkSize = 1 << 20
+
+// HtmlToPdfStream prints the request into w as the pdf is read from the
+// browser, never holding the whole document in memory unless it has
+// inserts. Once a chunk has been written the render is not retried, a
+// later failure is returned as is and leaves a truncated document in w.
+func (r *html2PdfService) HtmlToPdfStream(request dtos.HtmlRequest, w io.Writer) (dtos.PdfResponse, error) {
+	resp := new(dtos.PdfResponse)
+	var written int64
+	var printErr error
+	err := r.render(context.Background(), request, func(url string, request dtos.HtmlRequest) chromedp.Tasks {
+		return chromedp.Tasks{
+			chromedp.ActionFunc(func(ctx context.Context) error {
+				*resp = dtos.PdfResponse{}
+				return nil
+			}),
+			r.pageTasks(url, request, &resp.Scripts, &resp.BlockedRequests,
+				chromedp.ActionFunc(func(ctx context.Context) error {
+					if len(request.Inserts) > 0 {
+						var content []byte
+						if printErr = r.DoPdfActions(&content, request, ctx); printErr != nil {
+							return printErr
+						}
+						n, err := w.Write(content)
+						written, printErr = int64(n), err
+						return printErr
+					}
+					if request, printErr = r.fitPageHeight(ctx, request); printErr != nil {
+						return printErr
+					}
+					written, printErr = printTo(ctx, request, w, r.maxPdfSize)
+					return printErr
+				})),
+		}
+	}, func() bool {
+		return written > 0
+	})
+	if written > 0 && printErr != nil {
+		return *resp, printErr
+	}
+	return *resp, err
+}
+
+// printTo prints the page in stream transfer mode, copying the stream into
+// w chunk by chunk. It fails with ErrOutputTooLarge as soon as the document
+// exceeds maxSize bytes, when maxSize is positive.
+func printTo(ctx context.Context, request dtos.HtmlRequest, w io.Writer, maxSize int64) (int64, error) {
+	return printStream(ctx, printParams(request), w, maxSize)
+}
+
+// printStream prints the page with params as printTo does.
+func printStream(ctx context.Context, params *page.PrintToPDFParams, w io.Writer, maxSize int64) (int64, error) {
+	_, stream, err := params.
+		WithTransferMode(page.PrintToPDFTransferModeReturnAsStream).
+		Do(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer cdpio.Close(stream).Do(ctx)
+
+	var written int64
+	for {
+		var chunk cdpio.ReadReturns
+		if err := cdp.Execute(ctx, cdpio.CommandRead, cdpio.Read(stream).WithSize(printChunkSize), &chunk); err != nil {
+			return written, err
+		}
+		data := []byte(chunk.Data)
+		if chunk.Base64encoded {
+			if data, err = base64.StdEncoding.DecodeString(chunk.Data); err != nil {
+				return written, err
+			}
+		}
+		if maxSize > 0 && written+int64(len(data)) > maxSize {
+			return written, fmt.Errorf("%w: pdf exceeds %d bytes", ErrOutputTooLarge, maxSize)
+		}
+		n, err := w.Write(data)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+		if chunk.EOF {
+			return written, nil
+		}
+	}
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// paperFormats are the width and height of the paper formats, in mm. The
+// thermal rolls have no height: they print with AutoHeight.
+var paperFormats = map[string][2]float64{
+	"A3":        {297, 420},
+	"A4":        {210, 297},
+	"A5":        {148, 210},
+	"Letter":    {215.9, 279.4},
+	"Legal":     {215.9, 355.6},
+	"Thermal58": {58, 0},
+	"Thermal80": {80, 0},
+}
+
+// The bounds of the print options, the ones of the browser for the scale.
+const (
+	minScale       = 0.1
+	maxScale       = 2.0
+	maxPaperLength = 200
+)
+
+// printDefaults are the print options of the default tags, shown by the
+// API documentation.
+var printDefaults = defaultPrintOptions()
+
+func defaultPrintOptions() dtos.PrintOptions {
+	var options dtos.PrintOptions
+	v := reflect.ValueOf(&options).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		tag, ok := v.Type().Field(i).Tag.Lookup("default")
+		if !ok {
+			continue
+		}
+		value := reflect.New(v.Field(i).Type().Elem())
+		if err := json.Unmarshal([]byte(tag), value.Interface()); err != nil {
+			panic(fmt.Sprintf("print option %s: invalid default %q", v.Type().Field(i).Name, tag))
+		}
+		v.Field(i).Set(value)
+	}
+	return options
+}
+
+// applyPrintOptions sets the print options the request leaves out to
+// copies of the ones given. A request naming its paper format keeps it,
+// the format given only applies when the request sets no paper size.
+func applyPrintOptions(request *dtos.HtmlRequest, options *dtos.PrintOptions) {
+	if options == nil {
+		return
+	}
+	for _, option := range []struct {
+		value **bool
+		def   *bool
+	}{
+		{&request.PrintBackground, options.PrintBackground},
+		{&request.PreferCSSPageSize, options.PreferCSSPageSize},
+		{&request.DisplayHeaderFooter, options.DisplayHeaderFooter},
+		{&request.Landscape, options.Landscape},
+		{&request.AutoHeight, options.AutoHeight},
+	} {
+		if *option.value == nil && option.def != nil {
+			value := *option.def
+			*option.value = &value
+		}
+	}
+	lengths := []struct {
+		value **dtos.Length
+		def   *dtos.Length
+	}{
+		{&request.MarginTop, options.MarginTop},
+		{&request.MarginBottom, options.MarginBottom},
+		{&request.MarginRight, options.MarginRight},
+		{&request.MarginLeft, options.MarginLeft},
+	}
+	if request.PaperFormat == "" {
+		if request.PaperWidth == nil && request.PaperHeight == nil {
+			request.PaperFormat = options.PaperFormat
+		}
+		lengths = append(lengths, []struct {
+			value **dtos.Length
+			def   *dtos.Length
+		}{
+			{&request.PaperWidth, options.PaperWidth},
+			{&request.PaperHeight, options.PaperHeight},
+		}...)
+	}
+	for _, option := range lengths {
+		if *option.value == nil && option.def != nil {
+			value := *option.def
+			*option.value = &value
+		}
+	}
+	if request.WithScale == nil && options.WithScale != nil {
+		scale := *options.WithScale
+		request.WithScale = &scale
+	}
+}
+
+// resolvePrintOptions sets the paper size of the format of the request and
+// the defaults of the print options it leaves out, and checks their range.
+// A roll format turns AutoHeight on unless the request turns it off.
+func resolvePrintOptions(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	if request.PaperFormat != "" {
+		name, size, ok := paperFormat(request.PaperFormat)
+		if !ok {
+			return request, fmt.Errorf("%w: unknown paper format %q, expected one of %s", ErrInvalidRequest, request.PaperFormat, strings.Join(paperFormatNames(), ", "))
+		}
+		width, height := dtos.Length(size[0]/25.4), dtos.Length(size[1]/25.4)
+		request.PaperFormat = name
+		if request.PaperWidth == nil {
+			request.PaperWidth = &width
+		}
+		if height == 0 {
+			// the page height is fitted to the content when printing
+			height = *printDefaults.PaperHeight
+			if request.AutoHeight == nil {
+				auto := true
+				request.AutoHeight = &auto
+			} else if !*request.AutoHeight {
+				return request, fmt.Errorf("%w: %s is a roll without a page height, it prints with AutoHeight", ErrInvalidRequest, name)
+			}
+		}
+		if request.PaperHeight == nil {
+			request.PaperHeight = &height
+		}
+	}
+	applyPrintOptions(&request, &printDefaults)
+	return request, checkPrintOptions(request.PrintOptions)
+}
+
+func paperFormat(name string) (string, [2]float64, bool) {
+	for format, size := range paperFormats {
+		if strings.EqualFold(format, name) {
+			return format, size, true
+		}
+	}
+	return "", [2]float64{}, false
+}
+
+func paperFormatNames() []string {
+	names := make([]string, 0, len(paperFormats))
+	for name := range paperFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checkPrintOptions checks the range of resolved print options: the scale,
+// the paper size and the margins, which must leave room for the content.
+func checkPrintOptions(options dtos.PrintOptions) error {
+	if scale := *options.WithScale; scale < minScale || scale > maxScale {
+		return fmt.Errorf("%w: WithScale must be between %g and %g, not %g", ErrInvalidRequest, minScale, maxScale, scale)
+	}
+	for _, length := range []struct {
+		name  string
+		value dtos.Length
+	}{
+		{"PaperWidth", *options.PaperWidth},
+		{"PaperHeight", *options.PaperHeight},
+	} {
+		if length.value <= 0 || length.value > maxPaperLength {
+			return fmt.Errorf("%w: %s must be above 0 and at most %din, not %gin", ErrInvalidRequest, length.name, maxPaperLength, float64(length.value))
+		}
+	}
+	for _, margin := range []struct {
+		name  string
+		value dtos.Length
+	}{
+		{"MarginTop", *options.MarginTop},
+		{"MarginBottom", *options.MarginBottom},
+		{"MarginRight", *options.MarginRight},
+		{"MarginLeft", *options.MarginLeft},
+	} {
+		if margin.value < 0 {
+			return fmt.Errorf("%w: %s cannot be negative, not %gin", ErrInvalidRequest, margin.name, float64(margin.value))
+		}
+	}
+
+	if *options.AutoHeight && (*options.Landscape || *options.PreferCSSPageSize) {
+		return fmt.Errorf("%w: AutoHeight excludes Landscape and PreferCSSPageSize", ErrInvalidRequest)
+	}
+
+	// the margins apply to the page as printed, turned in landscape
+	width, height := *options.PaperWidth, *options.PaperHeight
+	if *options.Landscape {
+		width, height = height, width
+	}
+	if *options.MarginLeft+*options.MarginRight >= width {
+		return fmt.Errorf("%w: MarginLeft and MarginRight leave no room on a page %gin wide", ErrInvalidRequest, float64(width))
+	}
+	if !*options.AutoHeight && *options.MarginTop+*options.MarginBottom >= height {
+		return fmt.Errorf("%w: MarginTop and MarginBottom leave no room on a page %gin high", ErrInvalidRequest, float64(height))
+	}
+	return nil
+}
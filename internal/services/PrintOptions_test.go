@@ -0,0 +1,107 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePrintOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TestResolvePrintOptionsDefaults", func(t *testing.T) {
+		request, err := services.ResolvePrintOptions(dtos.HtmlRequest{})
+
+		assert.NoError(t, err)
+		assert.False(t, *request.DisplayHeaderFooter)
+		assert.True(t, *request.PrintBackground)
+		assert.False(t, *request.Landscape)
+		assert.Equal(t, 1.0, *request.WithScale)
+		assert.Equal(t, dtos.Length(0), *request.MarginTop)
+		assert.Equal(t, dtos.Length(0), *request.MarginLeft)
+		assert.Equal(t, dtos.Length(8.5), *request.PaperWidth)
+		assert.Equal(t, dtos.Length(11), *request.PaperHeight)
+	})
+
+	t.Run("TestPrintParamsDefaults", func(t *testing.T) {
+		// a request leaving the options out prints with the settings of
+		// the browser
+		params := services.PrintParams(dtos.HtmlRequest{})
+
+		assert.False(t, params.DisplayHeaderFooter)
+		assert.True(t, params.PrintBackground)
+		assert.Equal(t, 1.0, params.Scale)
+		assert.Equal(t, 8.5, params.PaperWidth)
+		assert.Equal(t, 11.0, params.PaperHeight)
+		assert.Zero(t, params.MarginTop+params.MarginRight+params.MarginBottom+params.MarginLeft)
+	})
+
+	t.Run("TestResolvePrintOptionsExplicitZero", func(t *testing.T) {
+		zero := dtos.Length(0)
+		no := false
+		request, err := services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: dtos.PrintOptions{MarginTop: &zero, DisplayHeaderFooter: &no}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, dtos.Length(0), *request.MarginTop)
+		assert.False(t, *request.DisplayHeaderFooter)
+	})
+
+	t.Run("TestPrintParamsBackground", func(t *testing.T) {
+		for _, background := range []bool{true, false} {
+			request := dtos.HtmlRequest{PrintOptions: dtos.PrintOptions{PrintBackground: &background}}
+
+			assert.Equal(t, background, services.PrintParams(request).PrintBackground)
+		}
+	})
+
+	t.Run("TestResolvePrintOptionsFormat", func(t *testing.T) {
+		for format, size := range map[string][2]float64{
+			"a3":     {11.69, 16.54},
+			"A5":     {5.83, 8.27},
+			"letter": {8.5, 11},
+			"Legal":  {8.5, 14},
+		} {
+			margin := dtos.Length(0.1)
+			request, err := services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: dtos.PrintOptions{PaperFormat: format, MarginLeft: &margin}})
+
+			assert.NoError(t, err, format)
+			assert.InDelta(t, size[0], float64(*request.PaperWidth), 0.01, format)
+			assert.InDelta(t, size[1], float64(*request.PaperHeight), 0.01, format)
+		}
+	})
+
+	t.Run("TestResolvePrintOptionsRoll", func(t *testing.T) {
+		request, err := services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: dtos.PrintOptions{PaperFormat: "thermal80"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Thermal80", request.PaperFormat)
+		assert.InDelta(t, 3.15, float64(*request.PaperWidth), 0.01)
+		assert.True(t, *request.AutoHeight)
+
+		no := false
+		_, err = services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: dtos.PrintOptions{PaperFormat: "Thermal58", AutoHeight: &no}})
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestResolvePrintOptionsInvalid", func(t *testing.T) {
+		scale, negative, wide, three := 0.05, dtos.Length(-1), dtos.Length(6), dtos.Length(3)
+		yes := true
+		for name, options := range map[string]dtos.PrintOptions{
+			"Scale":          {WithScale: &scale},
+			"NegativeMargin": {MarginBottom: &negative},
+			"NegativeWidth":  {PaperWidth: &negative},
+			"UnknownFormat":  {PaperFormat: "B5"},
+			"MarginsTooWide": {PaperFormat: "A5", MarginTop: &wide, MarginBottom: &wide},
+			"Landscape":      {PaperFormat: "A5", Landscape: &yes, MarginTop: &three, MarginBottom: &three},
+		} {
+			_, err := services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: options})
+
+			assert.ErrorIs(t, err, services.ErrInvalidRequest, name)
+		}
+
+		_, err := services.ResolvePrintOptions(dtos.HtmlRequest{PrintOptions: dtos.PrintOptions{PaperFormat: "A5", MarginTop: &three, MarginBottom: &three}})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,113 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"go.uber.org/zap"
+)
+
+// profileService reads the rendering profiles, each kept in dir as
+// <name>.json. They are read on use, for the operators to change them
+// without a restart.
+type profileService struct {
+	logger logger.Logger
+	dir    string
+}
+
+func NewProfileService(l logger.Logger) interfaces.ProfileServiceInterface {
+	return newProfileService(l, configs.GetConfig().Profiles.Dir)
+}
+
+func newProfileService(l logger.Logger, dir string) *profileService {
+	return &profileService{logger: l, dir: dir}
+}
+
+// List returns the profiles by name, leaving out and logging the ones that
+// cannot be read.
+func (s *profileService) List() ([]dtos.Profile, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []dtos.Profile{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	profiles := []dtos.Profile{}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".json")
+		if !ok || entry.IsDir() || !scriptNameExpr.MatchString(name) {
+			continue
+		}
+		profile, err := s.get(name)
+		if err != nil {
+			s.logger.Error("Invalid profile", zap.String("profile", name), zap.Error(err))
+			continue
+		}
+		profiles = append(profiles, profile)
+	}
+	sort.Slice(profiles, func(i, j int) bool { return profiles[i].Name < profiles[j].Name })
+	return profiles, nil
+}
+
+// get reads the profile named name, checking its paper format.
+func (s *profileService) get(name string) (dtos.Profile, error) {
+	if !scriptNameExpr.MatchString(name) {
+		return dtos.Profile{}, fmt.Errorf("%w: invalid profile name %q", ErrInvalidRequest, name)
+	}
+	b, err := os.ReadFile(filepath.Join(s.dir, name+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return dtos.Profile{}, fmt.Errorf("%w: unknown profile %q", ErrInvalidRequest, name)
+	}
+	if err != nil {
+		return dtos.Profile{}, err
+	}
+	var profile dtos.Profile
+	if err := json.Unmarshal(b, &profile); err != nil {
+		return dtos.Profile{}, fmt.Errorf("profile %s: %w", name, err)
+	}
+	if profile.PaperFormat != "" {
+		if _, _, ok := paperFormat(profile.PaperFormat); !ok {
+			return dtos.Profile{}, fmt.Errorf("profile %s: unknown paper format %q", name, profile.PaperFormat)
+		}
+		if profile.PaperWidth != nil || profile.PaperHeight != nil {
+			return dtos.Profile{}, fmt.Errorf("profile %s: PaperFormat excludes PaperWidth and PaperHeight", name)
+		}
+	}
+	profile.Name = name
+	return profile, nil
+}
+
+// applyProfile sets the settings the request leaves out to the ones of its
+// profile. The request returned names no profile, as applied.
+func (r *html2PdfService) applyProfile(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	if request.Profile == "" {
+		return request, nil
+	}
+	profile, err := r.profiles.get(request.Profile)
+	if err != nil {
+		return request, err
+	}
+	applyPrintOptions(&request, &profile.PrintOptions)
+	if request.HeaderTemplate == "" {
+		request.HeaderTemplate = profile.HeaderTemplate
+	}
+	if request.FooterTemplate == "" {
+		request.FooterTemplate = profile.FooterTemplate
+	}
+	if profile.Css != "" {
+		request.BaseStylesheets = append([]dtos.Stylesheet{{Name: "profile", Content: profile.Css}}, request.BaseStylesheets...)
+	}
+	request.Profile = ""
+	return request, nil
+}
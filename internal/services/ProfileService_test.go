@@ -0,0 +1,117 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileService(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "receipt-80mm.json"), []byte(`{
+		"Description": "Till receipts",
+		"PaperFormat": "Thermal80",
+		"MarginTop": "2mm", "MarginBottom": "2mm", "MarginLeft": "3mm", "MarginRight": "3mm",
+		"WithScale": 1,
+		"DisplayHeaderFooter": false,
+		"FooterTemplate": "<span>profile</span>",
+		"Css": "body { font-size: 9pt }"
+	}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "contract-a4.json"), []byte(`{"PaperFormat": "A4", "WithScale": 0.8}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "broken.json"), []byte(`{"MarginTop": "2 furlongs"}`), 0o644)
+	os.WriteFile(filepath.Join(dir, "readme.txt"), []byte(`notes`), 0o644)
+
+	templates := t.TempDir()
+	scale, landscape := 0.5, true
+	_, err := services.NewTemplateServiceWithDir(templates).Create(dtos.TemplateRequest{ID: "letter", TemplateVersion: dtos.TemplateVersion{
+		Content:        "<p>{{.Name}}</p>",
+		HeaderTemplate: "<span>template</span>",
+		Options:        &dtos.PrintOptions{WithScale: &scale, Landscape: &landscape},
+	}})
+	assert.NoError(t, err)
+	hs := services.NewHtml2PdfServiceWithProfiles(dir, templates)
+
+	t.Run("TestProfileServiceList", func(t *testing.T) {
+		profiles, err := services.NewProfileServiceWithDir(dir).List()
+
+		assert.NoError(t, err)
+		if assert.Len(t, profiles, 2) {
+			assert.Equal(t, "contract-a4", profiles[0].Name)
+			assert.Equal(t, "receipt-80mm", profiles[1].Name)
+			assert.Equal(t, "Till receipts", profiles[1].Description)
+		}
+	})
+
+	t.Run("TestProfileServiceListWithoutDir", func(t *testing.T) {
+		profiles, err := services.NewProfileServiceWithDir(filepath.Join(dir, "missing")).List()
+
+		assert.NoError(t, err)
+		assert.Empty(t, profiles)
+	})
+
+	t.Run("TestProfileServiceApply", func(t *testing.T) {
+		marginLeft := dtos.Length(0.5)
+		request, err := hs.ResolveRequest(dtos.HtmlRequest{
+			Profile:      "receipt-80mm",
+			Content:      "<p></p>",
+			PrintOptions: dtos.PrintOptions{MarginLeft: &marginLeft},
+			Stylesheets:  []dtos.Stylesheet{{Content: "p { margin: 0 }"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Empty(t, request.Profile)
+		assert.InDelta(t, 80/25.4, float64(*request.PaperWidth), 1e-9)
+		assert.InDelta(t, 2/25.4, float64(*request.MarginTop), 1e-9)
+		assert.Equal(t, dtos.Length(0.5), *request.MarginLeft)
+		assert.Equal(t, 1.0, *request.WithScale)
+		assert.False(t, *request.DisplayHeaderFooter)
+		assert.Equal(t, "<span>profile</span>", request.FooterTemplate)
+		if assert.Len(t, request.BaseStylesheets, 1) {
+			assert.Equal(t, "profile", request.BaseStylesheets[0].Name)
+		}
+		assert.Len(t, request.Stylesheets, 1)
+	})
+
+	t.Run("TestProfileServiceCssBeforeRequestCss", func(t *testing.T) {
+		request, err := hs.ResolveRequest(dtos.HtmlRequest{
+			Profile:    "receipt-80mm",
+			Content:    "<p></p>",
+			ContentCss: "body { font-size: 12pt }",
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `<style data-name="profile">body { font-size: 9pt }</style><style>body { font-size: 12pt }</style>`, services.StyleElements(request))
+	})
+
+	t.Run("TestProfileServiceOverTemplate", func(t *testing.T) {
+		request, err := hs.ResolveRequest(dtos.HtmlRequest{
+			Profile:      "contract-a4",
+			TemplateName: "letter",
+			Data:         map[string]interface{}{"Name": "Ana"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "<p>Ana</p>", request.Content)
+		assert.Equal(t, 0.8, *request.WithScale)
+		assert.True(t, *request.Landscape)
+		assert.Equal(t, "<span>template</span>", request.HeaderTemplate)
+		assert.Equal(t, "A4", request.PaperFormat)
+	})
+
+	t.Run("TestProfileServiceInvalid", func(t *testing.T) {
+		for _, name := range []string{"missing", "../receipt-80mm"} {
+			_, err := hs.ResolveRequest(dtos.HtmlRequest{Profile: name, Content: "<p></p>"})
+
+			assert.ErrorIs(t, err, services.ErrInvalidRequest, name)
+		}
+
+		_, err := hs.ResolveRequest(dtos.HtmlRequest{Profile: "broken", Content: "<p></p>"})
+		assert.Error(t, err)
+	})
+}
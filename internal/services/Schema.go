@@ -0,0 +1,618 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/mail"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// SchemaViolation is a value of the data, located by its JSON pointer,
+// breaking a rule of the schema.
+type SchemaViolation struct {
+	Pointer string
+	Message string
+}
+
+// SchemaError reports the data of a request not matching its schema, or
+// missing the values of its placeholders.
+type SchemaError struct {
+	Violations []SchemaViolation
+}
+
+func (e *SchemaError) Error() string {
+	v := e.Violations[0]
+	if len(e.Violations) == 1 {
+		return fmt.Sprintf("invalid data: %s %s", pointerName(v.Pointer), v.Message)
+	}
+	return fmt.Sprintf("invalid data: %s %s, and %d more violations", pointerName(v.Pointer), v.Message, len(e.Violations)-1)
+}
+
+func pointerName(pointer string) string {
+	if pointer == "" {
+		return "the data"
+	}
+	return pointer
+}
+
+// maxSchemaDepth bounds the nesting of the schemas applied to a value,
+// references included.
+const maxSchemaDepth = 100
+
+// maxSchemaEvaluations bounds the schemas applied to the values of the
+// data, so that references combined with allOf, anyOf or oneOf cannot make
+// the validation exponential.
+const maxSchemaEvaluations = 100000
+
+// schemaKeywords are the keywords the validator applies, or ignores as
+// annotations. A schema using any other one is refused, rather than looking
+// enforced when it is not.
+var schemaKeywords = map[string]bool{
+	"$schema": true, "$id": true, "$comment": true, "$defs": true, "definitions": true,
+	"title": true, "description": true, "default": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true,
+	"contentEncoding": true, "contentMediaType": true,
+	"$ref": true, "type": true, "enum": true, "const": true,
+	"minLength": true, "maxLength": true, "pattern": true, "format": true,
+	"minimum": true, "maximum": true, "exclusiveMinimum": true, "exclusiveMaximum": true, "multipleOf": true,
+	"minItems": true, "maxItems": true, "uniqueItems": true, "prefixItems": true, "items": true, "additionalItems": true,
+	"minProperties": true, "maxProperties": true, "required": true, "properties": true, "additionalProperties": true,
+	"allOf": true, "anyOf": true, "oneOf": true, "not": true,
+}
+
+// validateData checks the data of the request against its schema.
+func (r *html2PdfService) validateData(request dtos.HtmlRequest) error {
+	schema := request.Schema
+	if request.SchemaName != "" {
+		if !scriptNameExpr.MatchString(request.SchemaName) {
+			return fmt.Errorf("%w: invalid schema name %q", ErrInvalidRequest, request.SchemaName)
+		}
+		b, err := os.ReadFile(filepath.Join(r.schemasDir, request.SchemaName+".json"))
+		if err != nil {
+			return fmt.Errorf("%w: unknown schema %q", ErrInvalidRequest, request.SchemaName)
+		}
+		if err := json.Unmarshal(b, &schema); err != nil {
+			return fmt.Errorf("%w: schema %s: %s", ErrInvalidRequest, request.SchemaName, err)
+		}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	var data interface{}
+	if request.Data != nil {
+		data = request.Data
+	}
+	v := &schemaValidator{root: schema, patterns: map[string]*regexp.Regexp{}, evaluations: new(int)}
+	v.check(schema, "#", 0)
+	v.validate(schema, data, "", "#", 0)
+	if v.err != nil {
+		return v.err
+	}
+	if len(v.violations) > 0 {
+		return &SchemaError{Violations: v.violations}
+	}
+	return nil
+}
+
+// schemaValidator applies the JSON Schema keywords of the drafts 7 and
+// 2020-12 describing the values themselves: type, enum and const, the
+// string, number, array and object constraints, allOf, anyOf, oneOf and
+// not, and the references within the schema. The violations are collected
+// and a broken schema stops the validation with err. The evaluations are
+// counted across the validators of the subschemas.
+type schemaValidator struct {
+	root        map[string]interface{}
+	patterns    map[string]*regexp.Regexp
+	evaluations *int
+	violations  []SchemaViolation
+	err         error
+}
+
+func (v *schemaValidator) violation(pointer string, format string, args ...interface{}) {
+	v.violations = append(v.violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf(format, args...)})
+}
+
+func (v *schemaValidator) invalid(location string, format string, args ...interface{}) {
+	if v.err == nil {
+		v.err = fmt.Errorf("%w: invalid schema at %s: %s", ErrInvalidRequest, location, fmt.Sprintf(format, args...))
+	}
+}
+
+// matches reports whether value satisfies schema, without recording the
+// violations.
+func (v *schemaValidator) matches(schema interface{}, value interface{}, location string, depth int) bool {
+	sub := &schemaValidator{root: v.root, patterns: v.patterns, evaluations: v.evaluations}
+	sub.validate(schema, value, "", location, depth)
+	if sub.err != nil && v.err == nil {
+		v.err = sub.err
+	}
+	return len(sub.violations) == 0
+}
+
+func (v *schemaValidator) validate(schema interface{}, value interface{}, pointer string, location string, depth int) {
+	if v.err != nil {
+		return
+	}
+	if depth > maxSchemaDepth {
+		v.invalid(location, "schemas nested too deep")
+		return
+	}
+	if *v.evaluations++; *v.evaluations > maxSchemaEvaluations {
+		v.invalid(location, "schema too expensive to evaluate")
+		return
+	}
+	switch s := schema.(type) {
+	case bool:
+		if !s {
+			v.violation(pointer, "is not allowed")
+		}
+		return
+	case map[string]interface{}:
+		v.keywords(s, value, pointer, location, depth)
+	default:
+		v.invalid(location, "a schema must be an object or a boolean")
+	}
+}
+
+// check walks the schema and its subschemas, refusing the keywords the
+// validator does not support.
+func (v *schemaValidator) check(schema interface{}, location string, depth int) {
+	s, ok := schema.(map[string]interface{})
+	if !ok || v.err != nil {
+		return
+	}
+	if depth > maxSchemaDepth {
+		v.invalid(location, "schemas nested too deep")
+		return
+	}
+	keys := make([]string, 0, len(s))
+	for key := range s {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !schemaKeywords[key] {
+			v.invalid(location, "unsupported keyword %s", key)
+			return
+		}
+		sub := location + "/" + escapePointer(key)
+		switch key {
+		case "properties", "$defs", "definitions":
+			if schemas, ok := s[key].(map[string]interface{}); ok {
+				for name, schema := range schemas {
+					v.check(schema, sub+"/"+escapePointer(name), depth+1)
+				}
+			}
+		case "items", "prefixItems", "allOf", "anyOf", "oneOf":
+			if schemas, ok := s[key].([]interface{}); ok {
+				for i, schema := range schemas {
+					v.check(schema, fmt.Sprintf("%s/%d", sub, i), depth+1)
+				}
+			} else {
+				v.check(s[key], sub, depth+1)
+			}
+		case "additionalProperties", "additionalItems", "not":
+			v.check(s[key], sub, depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) keywords(s map[string]interface{}, value interface{}, pointer string, location string, depth int) {
+	if ref, ok := s["$ref"]; ok {
+		target, err := v.resolve(ref)
+		if err != nil {
+			v.invalid(location, "%s", err)
+			return
+		}
+		v.validate(target, value, pointer, fmt.Sprint(ref), depth+1)
+	}
+
+	if t, ok := s["type"]; ok && !v.checkType(t, value, location) {
+		v.violation(pointer, "must be of type %s", typeNames(t))
+		return
+	}
+	if enum, ok := s["enum"]; ok {
+		values, ok := enum.([]interface{})
+		if !ok {
+			v.invalid(location+"/enum", "an array is expected")
+			return
+		}
+		found := false
+		for _, e := range values {
+			found = found || jsonEqual(e, value)
+		}
+		if !found {
+			v.violation(pointer, "must be one of %s", jsonText(enum))
+		}
+	}
+	if c, ok := s["const"]; ok && !jsonEqual(c, value) {
+		v.violation(pointer, "must be %s", jsonText(c))
+	}
+
+	switch value := value.(type) {
+	case string:
+		v.stringKeywords(s, value, pointer, location)
+	case []interface{}:
+		v.arrayKeywords(s, value, pointer, location, depth)
+	case map[string]interface{}:
+		v.objectKeywords(s, value, pointer, location, depth)
+	case nil, bool:
+	default:
+		if n, err := toFloat(value); err == nil {
+			v.numberKeywords(s, n, pointer, location)
+		}
+	}
+
+	v.combinators(s, value, pointer, location, depth)
+}
+
+func (v *schemaValidator) combinators(s map[string]interface{}, value interface{}, pointer string, location string, depth int) {
+	if all, ok := s["allOf"]; ok {
+		for i, sub := range v.schemas(all, location+"/allOf") {
+			v.validate(sub, value, pointer, fmt.Sprintf("%s/allOf/%d", location, i), depth+1)
+		}
+	}
+	if anyOf, ok := s["anyOf"]; ok {
+		found := false
+		for i, sub := range v.schemas(anyOf, location+"/anyOf") {
+			found = found || v.matches(sub, value, fmt.Sprintf("%s/anyOf/%d", location, i), depth+1)
+		}
+		if !found {
+			v.violation(pointer, "must match at least one of the anyOf schemas")
+		}
+	}
+	if oneOf, ok := s["oneOf"]; ok {
+		n := 0
+		for i, sub := range v.schemas(oneOf, location+"/oneOf") {
+			if v.matches(sub, value, fmt.Sprintf("%s/oneOf/%d", location, i), depth+1) {
+				n++
+			}
+		}
+		if n != 1 {
+			v.violation(pointer, "must match exactly one of the oneOf schemas, matches %d", n)
+		}
+	}
+	if not, ok := s["not"]; ok && v.matches(not, value, location+"/not", depth+1) {
+		v.violation(pointer, "must not match the not schema")
+	}
+}
+
+func (v *schemaValidator) schemas(o interface{}, location string) []interface{} {
+	schemas, ok := o.([]interface{})
+	if !ok || len(schemas) == 0 {
+		v.invalid(location, "a non empty array is expected")
+	}
+	return schemas
+}
+
+// resolve returns the schema a reference within the root schema points to.
+func (v *schemaValidator) resolve(ref interface{}) (interface{}, error) {
+	s, ok := ref.(string)
+	if !ok || !strings.HasPrefix(s, "#") {
+		return nil, fmt.Errorf("only the references within the schema, as #/$defs/name, are supported")
+	}
+	var target interface{} = v.root
+	if s == "#" {
+		return target, nil
+	}
+	if !strings.HasPrefix(s, "#/") {
+		return nil, fmt.Errorf("invalid reference %q", s)
+	}
+	for _, token := range strings.Split(s[2:], "/") {
+		token, _ = url.PathUnescape(token)
+		token = strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+		switch t := target.(type) {
+		case map[string]interface{}:
+			target, ok = t[token]
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			ok = err == nil && i >= 0 && i < len(t)
+			if ok {
+				target = t[i]
+			}
+		default:
+			ok = false
+		}
+		if !ok {
+			return nil, fmt.Errorf("unresolved reference %q", s)
+		}
+	}
+	return target, nil
+}
+
+func (v *schemaValidator) checkType(t interface{}, value interface{}, location string) bool {
+	types, ok := t.([]interface{})
+	if !ok {
+		types = []interface{}{t}
+	}
+	for _, t := range types {
+		name, ok := t.(string)
+		if !ok {
+			v.invalid(location+"/type", "a type name is expected")
+			return true
+		}
+		if hasType(name, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasType(name string, value interface{}) bool {
+	switch name {
+	case "null":
+		return value == nil
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "number", "integer":
+		switch value.(type) {
+		case nil, bool, string, []interface{}, map[string]interface{}:
+			return false
+		}
+		n, err := toFloat(value)
+		return err == nil && (name == "number" || n == math.Trunc(n))
+	}
+	return false
+}
+
+func typeNames(t interface{}) string {
+	if types, ok := t.([]interface{}); ok {
+		names := make([]string, len(types))
+		for i, t := range types {
+			names[i] = fmt.Sprint(t)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(t)
+}
+
+// limit reads a numeric keyword of the schema.
+func (v *schemaValidator) limit(s map[string]interface{}, keyword string, location string) (float64, bool) {
+	o, ok := s[keyword]
+	if !ok {
+		return 0, false
+	}
+	if _, isBool := o.(bool); isBool {
+		v.invalid(location+"/"+keyword, "a number is expected")
+		return 0, false
+	}
+	n, err := toFloat(o)
+	if err != nil {
+		v.invalid(location+"/"+keyword, "a number is expected")
+		return 0, false
+	}
+	return n, true
+}
+
+func (v *schemaValidator) stringKeywords(s map[string]interface{}, value string, pointer string, location string) {
+	length := float64(utf8.RuneCountInString(value))
+	if n, ok := v.limit(s, "minLength", location); ok && length < n {
+		v.violation(pointer, "must be at least %v characters long", n)
+	}
+	if n, ok := v.limit(s, "maxLength", location); ok && length > n {
+		v.violation(pointer, "must be at most %v characters long", n)
+	}
+	if p, ok := s["pattern"]; ok {
+		expr, ok := p.(string)
+		re, err := v.pattern(expr)
+		if !ok || err != nil {
+			v.invalid(location+"/pattern", "a regular expression is expected")
+			return
+		}
+		if !re.MatchString(value) {
+			v.violation(pointer, "must match the pattern %s", expr)
+		}
+	}
+	if f, ok := s["format"].(string); ok && !validFormat(f, value) {
+		v.violation(pointer, "must be a valid %s", f)
+	}
+}
+
+func (v *schemaValidator) pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns[expr]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(expr)
+	if err == nil {
+		v.patterns[expr] = re
+	}
+	return re, err
+}
+
+// validFormat checks the usual formats, the other ones being accepted as
+// annotations.
+func validFormat(format string, value string) bool {
+	switch format {
+	case "date":
+		_, err := time.Parse("2006-01-02", value)
+		return err == nil
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, value)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", value)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(value)
+		return err == nil && addr.Address == value
+	case "uri":
+		u, err := url.Parse(value)
+		return err == nil && u.Scheme != ""
+	case "uuid":
+		_, err := uuid.Parse(value)
+		return err == nil && len(value) == 36
+	}
+	return true
+}
+
+func (v *schemaValidator) numberKeywords(s map[string]interface{}, value float64, pointer string, location string) {
+	if n, ok := v.limit(s, "minimum", location); ok && value < n {
+		v.violation(pointer, "must be at least %v", n)
+	}
+	if n, ok := v.limit(s, "maximum", location); ok && value > n {
+		v.violation(pointer, "must be at most %v", n)
+	}
+	if n, ok := v.limit(s, "exclusiveMinimum", location); ok && value <= n {
+		v.violation(pointer, "must be greater than %v", n)
+	}
+	if n, ok := v.limit(s, "exclusiveMaximum", location); ok && value >= n {
+		v.violation(pointer, "must be less than %v", n)
+	}
+	if n, ok := v.limit(s, "multipleOf", location); ok {
+		if n <= 0 {
+			v.invalid(location+"/multipleOf", "a positive number is expected")
+			return
+		}
+		if q := value / n; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.violation(pointer, "must be a multiple of %v", n)
+		}
+	}
+}
+
+func (v *schemaValidator) arrayKeywords(s map[string]interface{}, value []interface{}, pointer string, location string, depth int) {
+	length := float64(len(value))
+	if n, ok := v.limit(s, "minItems", location); ok && length < n {
+		v.violation(pointer, "must have at least %v items", n)
+	}
+	if n, ok := v.limit(s, "maxItems", location); ok && length > n {
+		v.violation(pointer, "must have at most %v items", n)
+	}
+	if unique, _ := s["uniqueItems"].(bool); unique {
+		for i := range value {
+			for j := 0; j < i; j++ {
+				if jsonEqual(value[i], value[j]) {
+					v.violation(pointer, "must have unique items, %d and %d are equal", j, i)
+				}
+			}
+		}
+	}
+
+	// prefixItems and items as of 2020-12, items as an array of draft 7
+	prefix, _ := s["prefixItems"].([]interface{})
+	items, hasItems := s["items"]
+	if tuple, ok := items.([]interface{}); ok {
+		prefix, items = tuple, s["additionalItems"]
+		hasItems = items != nil
+	}
+	for i, item := range value {
+		itemPointer := pointer + "/" + strconv.Itoa(i)
+		if i < len(prefix) {
+			v.validate(prefix[i], item, itemPointer, fmt.Sprintf("%s/prefixItems/%d", location, i), depth+1)
+		} else if hasItems {
+			v.validate(items, item, itemPointer, location+"/items", depth+1)
+		}
+	}
+}
+
+func (v *schemaValidator) objectKeywords(s map[string]interface{}, value map[string]interface{}, pointer string, location string, depth int) {
+	length := float64(len(value))
+	if n, ok := v.limit(s, "minProperties", location); ok && length < n {
+		v.violation(pointer, "must have at least %v properties", n)
+	}
+	if n, ok := v.limit(s, "maxProperties", location); ok && length > n {
+		v.violation(pointer, "must have at most %v properties", n)
+	}
+	if required, ok := s["required"]; ok {
+		names, ok := required.([]interface{})
+		if !ok {
+			v.invalid(location+"/required", "an array is expected")
+			return
+		}
+		for _, name := range names {
+			if _, ok := value[fmt.Sprint(name)]; !ok {
+				v.violation(pointer+"/"+escapePointer(fmt.Sprint(name)), "is required")
+			}
+		}
+	}
+
+	properties, _ := s["properties"].(map[string]interface{})
+	additional, hasAdditional := s["additionalProperties"]
+	keys := make([]string, 0, len(value))
+	for key := range value {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		propertyPointer := pointer + "/" + escapePointer(key)
+		if property, ok := properties[key]; ok {
+			v.validate(property, value[key], propertyPointer, location+"/properties/"+escapePointer(key), depth+1)
+		} else if hasAdditional {
+			if allowed, ok := additional.(bool); ok && !allowed {
+				v.violation(propertyPointer, "is not an allowed property")
+				continue
+			}
+			v.validate(additional, value[key], propertyPointer, location+"/additionalProperties", depth+1)
+		}
+	}
+}
+
+func escapePointer(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// jsonEqual compares JSON values, the numbers by value whatever their type.
+func jsonEqual(a, b interface{}) bool {
+	switch a := a.(type) {
+	case []interface{}:
+		b, ok := b.([]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if !jsonEqual(a[i], b[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		b, ok := b.(map[string]interface{})
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for key, item := range a {
+			other, ok := b[key]
+			if !ok || !jsonEqual(item, other) {
+				return false
+			}
+		}
+		return true
+	}
+	if hasType("number", a) && hasType("number", b) {
+		x, _ := toFloat(a)
+		y, _ := toFloat(b)
+		return x == y
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func jsonText(o interface{}) string {
+	b, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Sprint(o)
+	}
+	return string(b)
+}
@@ -0,0 +1,182 @@
+package services_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func decode(t *testing.T, s string) map[string]interface{} {
+	var v map[string]interface{}
+	assert.NoError(t, json.Unmarshal([]byte(s), &v))
+	return v
+}
+
+func violations(err error) map[string]string {
+	var schemaErr *services.SchemaError
+	if !errors.As(err, &schemaErr) {
+		return nil
+	}
+	found := map[string]string{}
+	for _, v := range schemaErr.Violations {
+		found[v.Pointer] = v.Message
+	}
+	return found
+}
+
+func TestValidateData(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	hs := services.NewHtml2PdfServiceWithSchemas(dir)
+	contract := `{
+		"type": "object",
+		"required": ["customer", "amount", "items"],
+		"additionalProperties": false,
+		"properties": {
+			"customer": {"$ref": "#/$defs/customer"},
+			"amount": {"type": "number", "exclusiveMinimum": 0, "multipleOf": 0.01},
+			"currency": {"enum": ["EUR", "USD"]},
+			"signed": {"type": "string", "format": "date"},
+			"items": {"type": "array", "minItems": 1, "uniqueItems": true, "items": {"type": "string", "minLength": 2}}
+		},
+		"$defs": {
+			"customer": {
+				"type": "object",
+				"required": ["name", "email"],
+				"properties": {
+					"name": {"type": "string", "pattern": "^[A-Z]"},
+					"email": {"type": "string", "format": "email"},
+					"vat": {"anyOf": [{"type": "null"}, {"type": "string", "maxLength": 11}]}
+				}
+			}
+		}
+	}`
+	os.WriteFile(filepath.Join(dir, "contract.json"), []byte(contract), 0o644)
+
+	t.Run("TestValidateDataValid", func(t *testing.T) {
+		data := decode(t, `{"customer": {"name": "Ana", "email": "ana@example.com", "vat": null}, "amount": 10.5, "currency": "EUR", "signed": "2024-03-05", "items": ["ab", "cd"]}`)
+
+		assert.NoError(t, hs.ValidateData(dtos.HtmlRequest{SchemaName: "contract", Data: data}))
+		assert.NoError(t, hs.ValidateData(dtos.HtmlRequest{Schema: decode(t, contract), Data: data}))
+	})
+
+	t.Run("TestValidateDataViolations", func(t *testing.T) {
+		data := decode(t, `{"customer": {"name": "ana", "email": "ana", "vat": "PT1234567890"}, "amount": 0, "currency": "GBP", "signed": "05/03/2024", "items": ["ab", "ab", "c"], "notes": "x"}`)
+
+		err := hs.ValidateData(dtos.HtmlRequest{SchemaName: "contract", Data: data})
+
+		assert.Equal(t, map[string]string{
+			"/customer/name":  "must match the pattern ^[A-Z]",
+			"/customer/email": "must be a valid email",
+			"/customer/vat":   "must match at least one of the anyOf schemas",
+			"/amount":         "must be greater than 0",
+			"/currency":       `must be one of ["EUR","USD"]`,
+			"/signed":         "must be a valid date",
+			"/items":          "must have unique items, 0 and 1 are equal",
+			"/items/2":        "must be at least 2 characters long",
+			"/notes":          "is not an allowed property",
+		}, violations(err))
+	})
+
+	t.Run("TestValidateDataRequired", func(t *testing.T) {
+		err := hs.ValidateData(dtos.HtmlRequest{SchemaName: "contract", Data: decode(t, `{"customer": {"name": "Ana"}}`)})
+
+		assert.Equal(t, map[string]string{
+			"/amount":         "is required",
+			"/items":          "is required",
+			"/customer/email": "is required",
+		}, violations(err))
+		assert.Contains(t, err.Error(), "and 2 more violations")
+
+		err = hs.ValidateData(dtos.HtmlRequest{SchemaName: "contract"})
+		assert.Equal(t, map[string]string{"": "must be of type object"}, violations(err))
+	})
+
+	t.Run("TestValidateDataInvalidSchema", func(t *testing.T) {
+		for name, schema := range map[string]string{
+			"Type":      `{"type": 1}`,
+			"Pattern":   `{"properties": {"a": {"pattern": "("}}}`,
+			"Ref":       `{"$ref": "#/$defs/missing"}`,
+			"RemoteRef": `{"$ref": "https://example.com/schema.json"}`,
+			"Loop":      `{"$ref": "#"}`,
+			"AnyOf":     `{"anyOf": []}`,
+			"If":        `{"if": {"required": ["a"]}, "then": {"required": ["b"]}}`,
+			"Nested":    `{"properties": {"b": {"dependentRequired": {"b": ["c"]}}}}`,
+			"Defs":      `{"$defs": {"unused": {"patternProperties": {"^x": false}}}}`,
+			"Contains":  `{"items": [{"contains": {"type": "string"}}]}`,
+		} {
+			err := hs.ValidateData(dtos.HtmlRequest{Schema: decode(t, schema), Data: map[string]interface{}{"a": "x"}})
+
+			assert.ErrorIs(t, err, services.ErrInvalidRequest, name)
+		}
+
+		err := hs.ValidateData(dtos.HtmlRequest{Schema: decode(t, `{"propertyNames": {"maxLength": 3}}`)})
+		assert.EqualError(t, err, "invalid request: invalid schema at #: unsupported keyword propertyNames")
+
+		for _, name := range []string{"missing", "../contract"} {
+			err := hs.ValidateData(dtos.HtmlRequest{SchemaName: name})
+
+			assert.ErrorIs(t, err, services.ErrInvalidRequest, name)
+		}
+	})
+
+	t.Run("TestValidateDataEvaluationBudget", func(t *testing.T) {
+		// every level applies the next one twice: 2^40 evaluations
+		defs := map[string]interface{}{"l40": map[string]interface{}{"type": "object"}}
+		for i := 0; i < 40; i++ {
+			next := map[string]interface{}{"$ref": fmt.Sprintf("#/$defs/l%d", i+1)}
+			defs[fmt.Sprintf("l%d", i)] = map[string]interface{}{"allOf": []interface{}{next, next}}
+		}
+		schema := map[string]interface{}{"$ref": "#/$defs/l0", "$defs": defs}
+
+		start := time.Now()
+		err := hs.ValidateData(dtos.HtmlRequest{Schema: schema, Data: map[string]interface{}{}})
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+		assert.ErrorContains(t, err, "schema too expensive to evaluate")
+		assert.Less(t, time.Since(start), 5*time.Second)
+	})
+
+	t.Run("TestFillPlaceholders", func(t *testing.T) {
+		request, err := hs.ResolveData(dtos.HtmlRequest{
+			Content: `<p>{{ customer.name }} owes {{amount}} for {{items.1}} {{paid}}</p>`,
+			Data:    decode(t, `{"customer": {"name": "<Ana>"}, "amount": 1250.5, "items": ["a", "b"], "paid": false}`),
+			Schema:  decode(t, `{"type": "object"}`),
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `<p>&lt;Ana&gt; owes 1250.5 for b false</p>`, request.Content)
+		assert.Nil(t, request.Data)
+		assert.Nil(t, request.Schema)
+	})
+
+	t.Run("TestFillPlaceholdersMissing", func(t *testing.T) {
+		_, err := hs.ResolveData(dtos.HtmlRequest{
+			Content: `{{customer.name}} {{customer.name}} {{customer}} {{items.5}} {{note}}`,
+			Data:    decode(t, `{"customer": {}, "items": [], "note": null}`),
+		})
+
+		assert.Equal(t, map[string]string{
+			"/customer/name": "has no value for the {{customer.name}} placeholder",
+			"/customer":      "has no value for the {{customer}} placeholder",
+			"/items/5":       "has no value for the {{items.5}} placeholder",
+			"/note":          "has no value for the {{note}} placeholder",
+		}, violations(err))
+	})
+
+	t.Run("TestFillPlaceholdersWithoutData", func(t *testing.T) {
+		request, err := hs.ResolveData(dtos.HtmlRequest{Content: "<script>var t = '{{name}}'</script>"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "<script>var t = '{{name}}'</script>", request.Content)
+	})
+}
@@ -0,0 +1,138 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// ErrInvalidRequest reports a request that cannot be rendered as sent.
+var ErrInvalidRequest = errors.New("invalid request")
+
+var scriptNameExpr = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// resolveScripts loads the source of the scripts referenced by name from
+// the scripts directory. Inline sources are kept as sent.
+func (r *html2PdfService) resolveScripts(scripts []dtos.Script) ([]dtos.Script, error) {
+	if len(scripts) == 0 {
+		return nil, nil
+	}
+	resolved := make([]dtos.Script, len(scripts))
+	for i, script := range scripts {
+		resolved[i] = script
+		if script.Source != "" {
+			continue
+		}
+		if !scriptNameExpr.MatchString(script.Name) {
+			return nil, fmt.Errorf("%w: script %d has no source and no valid name", ErrInvalidRequest, i)
+		}
+		source, err := os.ReadFile(filepath.Join(r.scriptsDir, script.Name+".js"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: unknown script %q", ErrInvalidRequest, script.Name)
+		}
+		resolved[i].Source = string(source)
+	}
+	return resolved, nil
+}
+
+// beforeLoadScripts builds the <script> elements running the beforeLoad
+// scripts while the document is parsed.
+func beforeLoadScripts(request dtos.HtmlRequest) string {
+	var b strings.Builder
+	for _, source := range beforeLoadSources(request) {
+		b.WriteString("<script>" + source + "</script>")
+	}
+	return b.String()
+}
+
+// beforeLoadSources wraps the beforeLoad scripts so that each one records
+// its outcome in window.__html2pdfScripts, to be collected once the page
+// loaded.
+func beforeLoadSources(request dtos.HtmlRequest) []string {
+	var sources []string
+	for i, script := range request.Scripts {
+		if script.Stage != dtos.ScriptBeforeLoad {
+			continue
+		}
+		// json.Marshal escapes <, > and &, the source cannot close the element.
+		source, _ := json.Marshal(script.Source)
+		sources = append(sources, fmt.Sprintf(`window.__html2pdfScripts = window.__html2pdfScripts || {};`+
+			`try { window.__html2pdfScripts[%d] = { value: JSON.stringify((0, eval)(%s)) }; }`+
+			` catch (e) { window.__html2pdfScripts[%d] = { error: String(e) }; }`, i, source, i))
+	}
+	return sources
+}
+
+// collectScripts reads the outcome of the beforeLoad scripts into results.
+func (r *html2PdfService) collectScripts(request dtos.HtmlRequest, results *[]dtos.ScriptResult) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		if len(beforeLoadSources(request)) == 0 {
+			return nil
+		}
+		var outcomes map[int]struct {
+			Value *string `json:"value"`
+			Error string  `json:"error"`
+		}
+		if err := chromedp.Evaluate(`window.__html2pdfScripts || {}`, &outcomes).Do(ctx); err != nil {
+			return err
+		}
+		for i, script := range request.Scripts {
+			if script.Stage != dtos.ScriptBeforeLoad {
+				continue
+			}
+			result := dtos.ScriptResult{Name: script.Name, Stage: script.Stage}
+			outcome, ok := outcomes[i]
+			switch {
+			case !ok:
+				result.Error = "script did not run"
+			case outcome.Error != "":
+				result.Error = outcome.Error
+			case outcome.Value != nil:
+				result.Value = json.RawMessage(*outcome.Value)
+			}
+			*results = append(*results, result)
+		}
+		return nil
+	}
+}
+
+// scriptActions runs the scripts of the given stage in declaration order,
+// awaiting the promises they return. An exception does not fail the render,
+// it is reported in the script result.
+func (r *html2PdfService) scriptActions(stage string, request dtos.HtmlRequest, results *[]dtos.ScriptResult) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		for _, script := range request.Scripts {
+			if script.Stage != stage {
+				continue
+			}
+			result := dtos.ScriptResult{Name: script.Name, Stage: script.Stage}
+			res, exp, err := runtime.Evaluate(script.Source).
+				WithReturnByValue(true).
+				WithAwaitPromise(true).
+				Do(ctx)
+			if err != nil {
+				return err
+			}
+			switch {
+			case exp != nil:
+				result.Error = exp.Text
+				if exp.Exception != nil && exp.Exception.Description != "" {
+					result.Error = exp.Exception.Description
+				}
+			case res != nil && len(res.Value) > 0:
+				result.Value = json.RawMessage(res.Value)
+			}
+			*results = append(*results, result)
+		}
+		return nil
+	}
+}
@@ -0,0 +1,53 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScripts(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "reveal.js"), []byte("document.body.hidden = false"), 0o600))
+	hs := services.NewHtml2PdfServiceWithScripts(dir)
+
+	t.Run("TestResolveStoredScript", func(t *testing.T) {
+		scripts, err := hs.ResolveScripts([]dtos.Script{
+			{Name: "reveal", Stage: dtos.ScriptAfterLoad},
+			{Name: "inline", Stage: dtos.ScriptBeforePrint, Source: "1 + 1"},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "document.body.hidden = false", scripts[0].Source)
+		assert.Equal(t, "1 + 1", scripts[1].Source)
+	})
+
+	t.Run("TestResolveUnknownScript", func(t *testing.T) {
+		_, err := hs.ResolveScripts([]dtos.Script{{Name: "missing", Stage: dtos.ScriptAfterLoad}})
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestResolveScriptNameOutsideDir", func(t *testing.T) {
+		_, err := hs.ResolveScripts([]dtos.Script{{Name: "../reveal", Stage: dtos.ScriptAfterLoad}})
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestBeforeLoadScriptsEscapeSource", func(t *testing.T) {
+		html := services.BeforeLoadScripts(dtos.HtmlRequest{Scripts: []dtos.Script{
+			{Stage: dtos.ScriptAfterLoad, Source: "ignored()"},
+			{Stage: dtos.ScriptBeforeLoad, Source: "'</script>'"},
+		}})
+
+		assert.Contains(t, html, "window.__html2pdfScripts[1]")
+		assert.NotContains(t, html, "ignored()")
+		assert.NotContains(t, html, "'</script>'")
+	})
+}
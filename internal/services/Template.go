@@ -0,0 +1,322 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// maxSeq bounds the sequences built by the seq template function.
+const maxSeq = 10000
+
+// templateFuncs are the helpers available to the request templates, besides
+// the html/template builtins:
+//
+//	formatDate "02/01/2006" .Date    a date, RFC 3339 or 2006-01-02, or unix seconds
+//	now                              the current time, for formatDate
+//	number 2 .Amount                 1,234.50
+//	formatNumber 2 "," "." .Amount   1.234,50
+//	default "n/a" .Name              the value, or the default when it is empty
+//	empty .Items                     whether the value is empty
+//	ternary "yes" "no" .Paid         the first value when the last one is not empty
+//	seq 3                            1 2 3, to range over
+//	add, sub, mul, div               arithmetic
+//	upper, lower, trim, replace, join, contains
+var templateFuncs = template.FuncMap{
+	"formatDate":   formatDate,
+	"now":          time.Now,
+	"number":       func(decimals int, v interface{}) (string, error) { return formatNumber(decimals, ".", ",", v) },
+	"formatNumber": formatNumber,
+	"default": func(def interface{}, v interface{}) interface{} {
+		if isEmpty(v) {
+			return def
+		}
+		return v
+	},
+	"empty": isEmpty,
+	"ternary": func(yes interface{}, no interface{}, cond interface{}) interface{} {
+		if isEmpty(cond) {
+			return no
+		}
+		return yes
+	},
+	"seq": func(n int) ([]int, error) {
+		if n > maxSeq {
+			return nil, fmt.Errorf("seq: %d exceeds %d", n, maxSeq)
+		}
+		s := []int{}
+		for i := 1; i <= n; i++ {
+			s = append(s, i)
+		}
+		return s, nil
+	},
+	"add": func(a, b interface{}) (float64, error) {
+		return arithmetic(a, b, func(x, y float64) float64 { return x + y })
+	},
+	"sub": func(a, b interface{}) (float64, error) {
+		return arithmetic(a, b, func(x, y float64) float64 { return x - y })
+	},
+	"mul": func(a, b interface{}) (float64, error) {
+		return arithmetic(a, b, func(x, y float64) float64 { return x * y })
+	},
+	"div": func(a, b interface{}) (float64, error) {
+		if y, err := toFloat(b); err == nil && y == 0 {
+			return 0, fmt.Errorf("div: division by zero")
+		}
+		return arithmetic(a, b, func(x, y float64) float64 { return x / y })
+	},
+	"upper":   strings.ToUpper,
+	"lower":   strings.ToLower,
+	"trim":    strings.TrimSpace,
+	"replace": func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+	"join": func(sep string, v interface{}) string {
+		items := reflect.ValueOf(v)
+		if items.Kind() != reflect.Slice && items.Kind() != reflect.Array {
+			return fmt.Sprint(v)
+		}
+		s := make([]string, items.Len())
+		for i := range s {
+			s[i] = fmt.Sprint(items.Index(i).Interface())
+		}
+		return strings.Join(s, sep)
+	},
+	"contains": strings.Contains,
+}
+
+// resolveData validates the data of the request against its schema and
+// builds the content with it, executing the template of the request or
+// filling the placeholders of its content. The request returned is left
+// without data, template or schema, as rendered.
+func (r *html2PdfService) resolveData(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	if err := r.validateData(request); err != nil {
+		return request, err
+	}
+	var err error
+	switch {
+	case request.Template != "" || request.TemplateName != "":
+		request, err = r.renderTemplate(request)
+	case request.Data != nil:
+		request.Content, err = fillPlaceholders(request.Content, request.Data)
+	}
+	request.Data, request.Schema, request.SchemaName = nil, nil, ""
+	return request, err
+}
+
+// placeholderExpr matches the {{field}} placeholders of a content, field
+// being a dotted path into the data. The names may hold single spaces, as
+// the columns of a spreadsheet do.
+var placeholderExpr = regexp.MustCompile(`\{\{\s*([\p{L}\p{N}_$-]+(?:[ .][\p{L}\p{N}_$-]+)*)\s*\}\}`)
+
+// fillPlaceholders replaces the placeholders of content with the escaped
+// data values they name. The placeholders naming no value, a null, an
+// object or an array are reported as violations.
+func fillPlaceholders(content string, data map[string]interface{}) (string, error) {
+	var violations []SchemaViolation
+	reported := map[string]bool{}
+	content = placeholderExpr.ReplaceAllStringFunc(content, func(match string) string {
+		path := placeholderExpr.FindStringSubmatch(match)[1]
+		text, pointer, ok := placeholderValue(data, path)
+		if !ok && !reported[pointer] {
+			reported[pointer] = true
+			violations = append(violations, SchemaViolation{Pointer: pointer, Message: fmt.Sprintf("has no value for the {{%s}} placeholder", path)})
+		}
+		return html.EscapeString(text)
+	})
+	if len(violations) > 0 {
+		return content, &SchemaError{Violations: violations}
+	}
+	return content, nil
+}
+
+// placeholderValue looks up the dotted path of a placeholder in data,
+// the fields naming object properties or array indexes. It returns the
+// text of the value, its JSON pointer, and whether it is a scalar value.
+func placeholderValue(data map[string]interface{}, path string) (string, string, bool) {
+	var value interface{} = data
+	pointer := ""
+	for _, field := range strings.Split(path, ".") {
+		pointer += "/" + escapePointer(field)
+		switch v := value.(type) {
+		case map[string]interface{}:
+			value = v[field]
+		case []interface{}:
+			i, err := strconv.Atoi(field)
+			value = nil
+			if err == nil && i >= 0 && i < len(v) {
+				value = v[i]
+			}
+		default:
+			value = nil
+		}
+	}
+
+	switch v := value.(type) {
+	case nil, map[string]interface{}, []interface{}:
+		return "", pointer, false
+	case string:
+		return v, pointer, true
+	case bool:
+		return strconv.FormatBool(v), pointer, true
+	case json.Number:
+		return v.String(), pointer, true
+	default:
+		f, err := toFloat(v)
+		return strconv.FormatFloat(f, 'f', -1, 64), pointer, err == nil
+	}
+}
+
+// renderTemplate returns the request with the content its template, inline or
+// from the registry, renders with the request data. The registry templates
+// also bring their CSS, their header and footer templates and their print
+// options, for the ones the request leaves out.
+func (r *html2PdfService) renderTemplate(request dtos.HtmlRequest) (dtos.HtmlRequest, error) {
+	if request.TemplateName == "" {
+		content, err := executeTemplate("inline", request.Template, request.Data)
+		request.Content, request.Template = content, ""
+		return request, err
+	}
+
+	if r.templates == nil {
+		return request, ErrRegistryUnavailable
+	}
+	v, err := r.templates.execute(request.TemplateName, request.Data)
+	if err != nil {
+		return request, err
+	}
+	request.Content, request.TemplateName = v.Content, ""
+	if v.Css != "" {
+		request.BaseStylesheets = append([]dtos.Stylesheet{{Name: "template", Content: v.Css}}, request.BaseStylesheets...)
+	}
+	if request.HeaderTemplate == "" {
+		request.HeaderTemplate = v.HeaderTemplate
+	}
+	if request.FooterTemplate == "" {
+		request.FooterTemplate = v.FooterTemplate
+	}
+	applyPrintOptions(&request, v.Options)
+	return request, nil
+}
+
+func executeTemplate(name string, source string, data map[string]interface{}) (string, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+	}
+	return run(tmpl, data)
+}
+
+func run(tmpl *template.Template, data map[string]interface{}) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+	}
+	return b.String(), nil
+}
+
+func isEmpty(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	value := reflect.ValueOf(v)
+	switch value.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return value.Len() == 0
+	}
+	return value.IsZero()
+}
+
+func toFloat(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return n, nil
+	case json.Number:
+		return n.Float64()
+	case string:
+		return strconv.ParseFloat(strings.TrimSpace(n), 64)
+	}
+	value := reflect.ValueOf(v)
+	switch {
+	case value.CanInt():
+		return float64(value.Int()), nil
+	case value.CanUint():
+		return float64(value.Uint()), nil
+	case value.CanFloat():
+		return value.Float(), nil
+	}
+	return 0, fmt.Errorf("%v is not a number", v)
+}
+
+func arithmetic(a, b interface{}, op func(x, y float64) float64) (float64, error) {
+	x, err := toFloat(a)
+	if err != nil {
+		return 0, err
+	}
+	y, err := toFloat(b)
+	if err != nil {
+		return 0, err
+	}
+	return op(x, y), nil
+}
+
+// formatNumber rounds v to decimals digits and groups the thousands of its
+// integer part.
+func formatNumber(decimals int, decimalSep string, thousandsSep string, v interface{}) (string, error) {
+	f, err := toFloat(v)
+	if err != nil {
+		return "", err
+	}
+	if decimals < 0 {
+		decimals = 0
+	}
+	digits := strconv.FormatFloat(math.Abs(f), 'f', decimals, 64)
+	integer, fraction, _ := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	if f < 0 && strings.Trim(digits, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, c := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteString(thousandsSep)
+		}
+		b.WriteRune(c)
+	}
+	if fraction != "" {
+		b.WriteString(decimalSep + fraction)
+	}
+	return b.String(), nil
+}
+
+var dateLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// formatDate formats a time, a date string or unix seconds with a Go time
+// layout.
+func formatDate(layout string, v interface{}) (string, error) {
+	if t, ok := v.(time.Time); ok {
+		return t.Format(layout), nil
+	}
+	if s, ok := v.(string); ok {
+		for _, l := range dateLayouts {
+			if t, err := time.Parse(l, strings.TrimSpace(s)); err == nil {
+				return t.Format(layout), nil
+			}
+		}
+		return "", fmt.Errorf("formatDate: %q is not a date", s)
+	}
+	seconds, err := toFloat(v)
+	if err != nil {
+		return "", fmt.Errorf("formatDate: %w", err)
+	}
+	return time.Unix(int64(seconds), 0).UTC().Format(layout), nil
+}
@@ -0,0 +1,279 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"strconv"
+	"strings"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/interfaces"
+	"github.com/kolzxx/html2pdf/internal/logger"
+)
+
+// templateService is the template registry. The templates are checked
+// against their layout and partials before they are stored.
+type templateService struct {
+	store interfaces.TemplateStore
+}
+
+// NewTemplateService returns the registry of the configured backend, or an
+// error when the backend is unknown.
+func NewTemplateService(l logger.Logger) (interfaces.TemplateServiceInterface, error) {
+	store, err := newTemplateStore(configs.GetConfig().Templates)
+	if err != nil {
+		return nil, err
+	}
+	return newTemplateService(store), nil
+}
+
+func newTemplateService(store interfaces.TemplateStore) *templateService {
+	return &templateService{store: store}
+}
+
+// newTemplateStore returns the storage backend of the configuration.
+func newTemplateStore(cfg configs.Templates) (interfaces.TemplateStore, error) {
+	if cfg.Backend != "filesystem" {
+		return nil, fmt.Errorf("unknown template backend %q", cfg.Backend)
+	}
+	return newFileTemplateStore(cfg.Dir), nil
+}
+
+// parseTemplateRef splits a reference, a template ID optionally followed by
+// @version, the version being 0 when there is none.
+func parseTemplateRef(ref string) (string, int, error) {
+	id, version, found := strings.Cut(ref, "@")
+	if !scriptNameExpr.MatchString(id) {
+		return "", 0, fmt.Errorf("%w: invalid template name %q", ErrInvalidRequest, ref)
+	}
+	if !found {
+		return id, 0, nil
+	}
+	n, err := strconv.Atoi(version)
+	if err != nil || n < 1 {
+		return "", 0, fmt.Errorf("%w: invalid template version %q", ErrInvalidRequest, ref)
+	}
+	return id, n, nil
+}
+
+func (s *templateService) List() ([]dtos.Template, error) {
+	return s.store.List()
+}
+
+// Get returns the template ref refers to, with the version it names or the
+// latest one.
+func (s *templateService) Get(ref string) (dtos.Template, error) {
+	id, version, err := parseTemplateRef(ref)
+	if err != nil {
+		return dtos.Template{}, err
+	}
+	template, err := s.store.Get(id)
+	if err != nil {
+		return template, err
+	}
+	v, err := s.store.GetVersion(id, version)
+	if err != nil {
+		return template, err
+	}
+	template.Version = &v
+	return template, nil
+}
+
+func (s *templateService) Create(request dtos.TemplateRequest) (dtos.Template, error) {
+	if !scriptNameExpr.MatchString(request.ID) {
+		return dtos.Template{}, fmt.Errorf("%w: invalid template name %q", ErrInvalidRequest, request.ID)
+	}
+	if request.Kind == "" {
+		request.Kind = dtos.TemplateDocument
+	}
+	if request.Kind != dtos.TemplateDocument && request.ID == "content" {
+		return dtos.Template{}, fmt.Errorf("%w: the content name is kept for the documents", ErrInvalidRequest)
+	}
+	if err := s.check(request.Kind, request.TemplateVersion); err != nil {
+		return dtos.Template{}, err
+	}
+	template := dtos.Template{ID: request.ID, Kind: request.Kind, Description: request.Description}
+	return s.store.Create(template, request.TemplateVersion)
+}
+
+// Update stores the request as a new version of the template.
+func (s *templateService) Update(id string, request dtos.TemplateRequest) (dtos.Template, error) {
+	if !scriptNameExpr.MatchString(id) {
+		return dtos.Template{}, fmt.Errorf("%w: invalid template name %q", ErrInvalidRequest, id)
+	}
+	current, err := s.store.Get(id)
+	if err != nil {
+		return current, err
+	}
+	if request.Kind != "" && request.Kind != current.Kind {
+		return current, fmt.Errorf("%w: template %s is a %s", ErrInvalidRequest, id, current.Kind)
+	}
+	if err := s.check(current.Kind, request.TemplateVersion); err != nil {
+		return current, err
+	}
+	return s.store.AddVersion(dtos.Template{ID: id, Description: request.Description}, request.TemplateVersion)
+}
+
+// Delete removes the template and its versions, unless a version of
+// another template still uses it as its layout or a partial.
+func (s *templateService) Delete(id string) error {
+	if !scriptNameExpr.MatchString(id) {
+		return fmt.Errorf("%w: invalid template name %q", ErrInvalidRequest, id)
+	}
+	users, err := s.users(id)
+	if err != nil {
+		return err
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("%w: template %s is used by %s", ErrTemplateInUse, id, strings.Join(users, ", "))
+	}
+	return s.store.Delete(id)
+}
+
+// users lists the templates having a version that refers to id as its
+// layout or one of its partials.
+func (s *templateService) users(id string) ([]string, error) {
+	templates, err := s.store.List()
+	if err != nil {
+		return nil, err
+	}
+	var users []string
+	for _, template := range templates {
+		if template.ID == id || template.Kind == dtos.TemplatePartial {
+			continue
+		}
+		for _, version := range template.Versions {
+			v, err := s.store.GetVersion(template.ID, version)
+			if err != nil {
+				return nil, err
+			}
+			if refersTo(v, id) {
+				users = append(users, template.ID)
+				break
+			}
+		}
+	}
+	return users, nil
+}
+
+func refersTo(v dtos.TemplateVersion, id string) bool {
+	for _, ref := range append([]string{v.Layout}, v.Partials...) {
+		if refID, _, _ := strings.Cut(ref, "@"); refID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// check parses a version of a kind of template, along with the layout and
+// partials it refers to.
+func (s *templateService) check(kind string, v dtos.TemplateVersion) error {
+	if kind != dtos.TemplateDocument && v.Layout != "" {
+		return fmt.Errorf("%w: only the documents have a layout", ErrInvalidRequest)
+	}
+	if kind == dtos.TemplatePartial && len(v.Partials) > 0 {
+		return fmt.Errorf("%w: the partials cannot have partials", ErrInvalidRequest)
+	}
+	if v.Options != nil && v.Options.PaperFormat != "" {
+		if _, _, ok := paperFormat(v.Options.PaperFormat); !ok {
+			return fmt.Errorf("%w: unknown paper format %q", ErrInvalidRequest, v.Options.PaperFormat)
+		}
+	}
+	if _, err := s.compile(v); err != nil {
+		return err
+	}
+	for _, source := range []string{v.HeaderTemplate, v.FooterTemplate} {
+		if _, err := template.New("").Funcs(templateFuncs).Parse(source); err != nil {
+			return fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+		}
+	}
+	return nil
+}
+
+// reference reads the version of a kind of template ref refers to.
+func (s *templateService) reference(ref string, kind string) (string, dtos.TemplateVersion, error) {
+	id, version, err := parseTemplateRef(ref)
+	if err != nil {
+		return "", dtos.TemplateVersion{}, err
+	}
+	template, err := s.store.Get(id)
+	if err != nil {
+		return "", dtos.TemplateVersion{}, templateError(ref, err)
+	}
+	if template.Kind != kind {
+		return "", dtos.TemplateVersion{}, fmt.Errorf("%w: template %s is a %s, not a %s", ErrInvalidRequest, id, template.Kind, kind)
+	}
+	v, err := s.store.GetVersion(id, version)
+	if err != nil {
+		return "", v, templateError(ref, err)
+	}
+	return id, v, nil
+}
+
+func templateError(ref string, err error) error {
+	if errors.Is(err, ErrTemplateNotFound) {
+		return fmt.Errorf("%w: unknown template %q", ErrInvalidRequest, ref)
+	}
+	return err
+}
+
+// compile parses the content of the version as the content template, with
+// its partials named by their ID. It returns the template to execute: the
+// layout, when there is one, which brings its own partials.
+func (s *templateService) compile(v dtos.TemplateVersion) (*template.Template, error) {
+	root, err := template.New("content").Funcs(templateFuncs).Parse(v.Content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err)
+	}
+	entry := root
+	partials := v.Partials
+	if v.Layout != "" {
+		id, layout, err := s.reference(v.Layout, dtos.TemplateLayout)
+		if err != nil {
+			return nil, err
+		}
+		if entry, err = root.New(id).Parse(layout.Content); err != nil {
+			return nil, fmt.Errorf("%w: layout %s: %s", ErrInvalidRequest, id, err)
+		}
+		partials = append(append([]string{}, partials...), layout.Partials...)
+	}
+	for _, ref := range partials {
+		id, partial, err := s.reference(ref, dtos.TemplatePartial)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := root.New(id).Parse(partial.Content); err != nil {
+			return nil, fmt.Errorf("%w: partial %s: %s", ErrInvalidRequest, id, err)
+		}
+	}
+	return entry, nil
+}
+
+// execute renders the document ref refers to with data. The content, header
+// and footer templates of the version returned are rendered.
+func (s *templateService) execute(ref string, data map[string]interface{}) (dtos.TemplateVersion, error) {
+	_, v, err := s.reference(ref, dtos.TemplateDocument)
+	if err != nil {
+		return v, err
+	}
+	tmpl, err := s.compile(v)
+	if err != nil {
+		return v, err
+	}
+	if v.Content, err = run(tmpl, data); err != nil {
+		return v, err
+	}
+	if v.HeaderTemplate != "" {
+		if v.HeaderTemplate, err = executeTemplate("header", v.HeaderTemplate, data); err != nil {
+			return v, err
+		}
+	}
+	if v.FooterTemplate != "" {
+		if v.FooterTemplate, err = executeTemplate("footer", v.FooterTemplate, data); err != nil {
+			return v, err
+		}
+	}
+	return v, nil
+}
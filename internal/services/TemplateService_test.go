@@ -0,0 +1,194 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTemplateService(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "plain.html"), []byte(`<p>{{.Name}}</p>`), 0o644)
+	ts := services.NewTemplateServiceWithDir(dir)
+	hs := services.NewHtml2PdfServiceWithTemplates(dir)
+
+	create := func(id string, kind string, v dtos.TemplateVersion) {
+		_, err := ts.Create(dtos.TemplateRequest{ID: id, Kind: kind, TemplateVersion: v})
+		assert.NoError(t, err, id)
+	}
+	create("signature", dtos.TemplatePartial, dtos.TemplateVersion{Content: `<i>{{.Name}}</i>`})
+	create("page", dtos.TemplateLayout, dtos.TemplateVersion{
+		Content:  `<main>{{template "content" .}}</main>{{template "signature" .}}`,
+		Partials: []string{"signature"},
+	})
+	create("address", dtos.TemplatePartial, dtos.TemplateVersion{Content: `<address>{{.}}</address>`})
+	scale := 0.8
+	landscape := true
+	create("invoice", "", dtos.TemplateVersion{
+		Content:        `<h1>{{.Name}}</h1>{{template "address" .City}}`,
+		Layout:         "page",
+		Partials:       []string{"address"},
+		Css:            "h1 { color: red }",
+		FooterTemplate: `<span>{{.Name}} <span class="pageNumber"></span></span>`,
+		Options:        &dtos.PrintOptions{WithScale: &scale, Landscape: &landscape},
+	})
+	data := map[string]interface{}{"Name": "Ana", "City": "Lisbon"}
+
+	t.Run("TestTemplateServiceRender", func(t *testing.T) {
+		marginTop := dtos.Length(1)
+		request, err := hs.ResolveTemplate(dtos.HtmlRequest{TemplateName: "invoice", Data: data, PrintOptions: dtos.PrintOptions{MarginTop: &marginTop}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "<main><h1>Ana</h1><address>Lisbon</address></main><i>Ana</i>", request.Content)
+		assert.Equal(t, "h1 { color: red }", request.BaseStylesheets[0].Content)
+		assert.Equal(t, `<span>Ana <span class="pageNumber"></span></span>`, request.FooterTemplate)
+		assert.Equal(t, 0.8, *request.WithScale)
+		assert.True(t, *request.Landscape)
+		assert.Equal(t, dtos.Length(1), *request.MarginTop)
+		assert.Empty(t, request.TemplateName)
+	})
+
+	t.Run("TestTemplateServiceRequestOverrides", func(t *testing.T) {
+		scale := 1.2
+		request, err := hs.ResolveTemplate(dtos.HtmlRequest{TemplateName: "invoice", Data: data, PrintOptions: dtos.PrintOptions{WithScale: &scale}, FooterTemplate: "<span></span>"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1.2, *request.WithScale)
+		assert.Equal(t, "<span></span>", request.FooterTemplate)
+	})
+
+	t.Run("TestTemplateServiceCssBeforeRequestCss", func(t *testing.T) {
+		request, err := hs.ResolveTemplate(dtos.HtmlRequest{
+			TemplateName: "invoice",
+			Data:         data,
+			ContentCss:   "h1 { color: blue }",
+			Stylesheets:  []dtos.Stylesheet{{Name: "brand", Content: "h1 { color: green }"}},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `<style data-name="template">h1 { color: red }</style><style>h1 { color: blue }</style>`+
+			`<style data-name="brand">h1 { color: green }</style>`, services.StyleElements(request))
+	})
+
+	t.Run("TestTemplateServiceVersions", func(t *testing.T) {
+		create("letter", "", dtos.TemplateVersion{Content: "v1"})
+		template, err := ts.Update("letter", dtos.TemplateRequest{Description: "Letter", TemplateVersion: dtos.TemplateVersion{Content: "v2"}})
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, template.Versions)
+		assert.Equal(t, "Letter", template.Description)
+
+		for ref, expected := range map[string]string{"letter": "v2", "letter@1": "v1", "letter@2": "v2"} {
+			content, err := hs.RenderTemplate(dtos.HtmlRequest{TemplateName: ref})
+			assert.NoError(t, err, ref)
+			assert.Equal(t, expected, content, ref)
+		}
+
+		template, err = ts.Get("letter@1")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, template.Version.Version)
+		_, err = ts.Get("letter@3")
+		assert.ErrorIs(t, err, services.ErrTemplateNotFound)
+	})
+
+	t.Run("TestTemplateServiceConcurrentUpdates", func(t *testing.T) {
+		create("memo", "", dtos.TemplateVersion{Content: "v1"})
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := ts.Update("memo", dtos.TemplateRequest{TemplateVersion: dtos.TemplateVersion{Content: "next"}})
+				assert.NoError(t, err)
+			}()
+		}
+		wg.Wait()
+
+		template, err := ts.Get("memo")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2, 3, 4, 5, 6, 7, 8, 9}, template.Versions)
+	})
+
+	t.Run("TestTemplateServicePlainFile", func(t *testing.T) {
+		template, err := ts.Get("plain")
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, template.Versions)
+		assert.Equal(t, `<p>{{.Name}}</p>`, template.Version.Content)
+
+		_, err = ts.Create(dtos.TemplateRequest{ID: "plain", TemplateVersion: dtos.TemplateVersion{Content: "x"}})
+		assert.ErrorIs(t, err, services.ErrTemplateExists)
+		_, err = ts.Update("plain", dtos.TemplateRequest{TemplateVersion: dtos.TemplateVersion{Content: "x"}})
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+
+		templates, err := ts.List()
+		assert.NoError(t, err)
+		ids := []string{}
+		for _, template := range templates {
+			ids = append(ids, template.ID)
+		}
+		assert.Contains(t, ids, "plain")
+		assert.Contains(t, ids, "invoice")
+	})
+
+	t.Run("TestTemplateServiceDelete", func(t *testing.T) {
+		create("draft", "", dtos.TemplateVersion{Content: "draft"})
+
+		assert.NoError(t, ts.Delete("draft"))
+		assert.ErrorIs(t, ts.Delete("draft"), services.ErrTemplateNotFound)
+		_, err := hs.RenderTemplate(dtos.HtmlRequest{TemplateName: "draft"})
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestTemplateServiceDeleteInUse", func(t *testing.T) {
+		err := ts.Delete("address")
+		assert.ErrorIs(t, err, services.ErrTemplateInUse)
+		assert.ErrorContains(t, err, "used by invoice")
+
+		err = ts.Delete("signature")
+		assert.ErrorIs(t, err, services.ErrTemplateInUse)
+		assert.ErrorContains(t, err, "used by page")
+
+		_, err = hs.RenderTemplate(dtos.HtmlRequest{TemplateName: "invoice", Data: data})
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestTemplateStoreUnknownBackend", func(t *testing.T) {
+		_, err := services.NewTemplateStore(configs.Templates{Dir: dir, Backend: "s3"})
+
+		assert.EqualError(t, err, `unknown template backend "s3"`)
+	})
+
+	t.Run("TestTemplateServiceInvalid", func(t *testing.T) {
+		for name, request := range map[string]dtos.TemplateRequest{
+			"BadID":          {ID: "../x", TemplateVersion: dtos.TemplateVersion{Content: "x"}},
+			"Syntax":         {ID: "a", TemplateVersion: dtos.TemplateVersion{Content: "{{.Name"}},
+			"FooterSyntax":   {ID: "a", TemplateVersion: dtos.TemplateVersion{Content: "x", FooterTemplate: "{{"}},
+			"UnknownLayout":  {ID: "a", TemplateVersion: dtos.TemplateVersion{Content: "x", Layout: "missing"}},
+			"LayoutKind":     {ID: "a", TemplateVersion: dtos.TemplateVersion{Content: "x", Layout: "signature"}},
+			"PartialKind":    {ID: "a", TemplateVersion: dtos.TemplateVersion{Content: "x", Partials: []string{"invoice"}}},
+			"BadVersion":     {ID: "a", TemplateVersion: dtos.TemplateVersion{Content: "x", Layout: "page@0"}},
+			"LayoutOfLayout": {ID: "a", Kind: dtos.TemplateLayout, TemplateVersion: dtos.TemplateVersion{Content: "x", Layout: "page"}},
+			"ContentPartial": {ID: "content", Kind: dtos.TemplatePartial, TemplateVersion: dtos.TemplateVersion{Content: "x"}},
+		} {
+			_, err := ts.Create(request)
+
+			assert.ErrorIs(t, err, services.ErrInvalidRequest, name)
+		}
+
+		_, err := ts.Update("signature", dtos.TemplateRequest{Kind: dtos.TemplateDocument, TemplateVersion: dtos.TemplateVersion{Content: "x"}})
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+		_, err = ts.Update("missing", dtos.TemplateRequest{TemplateVersion: dtos.TemplateVersion{Content: "x"}})
+		assert.ErrorIs(t, err, services.ErrTemplateNotFound)
+		_, err = ts.Create(dtos.TemplateRequest{ID: "invoice", TemplateVersion: dtos.TemplateVersion{Content: "x"}})
+		assert.ErrorIs(t, err, services.ErrTemplateExists)
+		_, err = hs.RenderTemplate(dtos.HtmlRequest{TemplateName: "signature"})
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+}
@@ -0,0 +1,260 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+var (
+	// ErrTemplateNotFound reports an unknown template or version.
+	ErrTemplateNotFound = errors.New("template not found")
+	// ErrTemplateExists reports a template created twice.
+	ErrTemplateExists = errors.New("template already exists")
+	// ErrTemplateInUse reports the deletion of a layout or partial other
+	// templates still refer to.
+	ErrTemplateInUse = errors.New("template in use")
+	// ErrRegistryUnavailable reports a registry template requested while
+	// the registry could not be set up.
+	ErrRegistryUnavailable = errors.New("template registry unavailable")
+)
+
+// templateMeta is the file describing a template in its directory.
+const templateMeta = "template.json"
+
+// fileTemplateStore keeps each template in a directory of dir, holding the
+// template description and a file per version. The versions are created
+// exclusively, so that concurrent updates number them apart. The plain
+// <id>.html files of dir are read only templates of a single version.
+type fileTemplateStore struct {
+	dir string
+}
+
+func newFileTemplateStore(dir string) *fileTemplateStore {
+	return &fileTemplateStore{dir: dir}
+}
+
+func (s *fileTemplateStore) List() ([]dtos.Template, error) {
+	entries, err := os.ReadDir(s.dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []dtos.Template{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	templates := []dtos.Template{}
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".html")
+		if !scriptNameExpr.MatchString(id) || entry.IsDir() == (id != entry.Name()) {
+			continue
+		}
+		template, err := s.Get(id)
+		if errors.Is(err, ErrTemplateNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		templates = append(templates, template)
+	}
+	sort.Slice(templates, func(i, j int) bool { return templates[i].ID < templates[j].ID })
+	return templates, nil
+}
+
+func (s *fileTemplateStore) Get(id string) (dtos.Template, error) {
+	var template dtos.Template
+	b, err := os.ReadFile(filepath.Join(s.dir, id, templateMeta))
+	if errors.Is(err, fs.ErrNotExist) {
+		return s.plain(id)
+	}
+	if err != nil {
+		return template, err
+	}
+	if err := json.Unmarshal(b, &template); err != nil {
+		return template, fmt.Errorf("template %s: %w", id, err)
+	}
+	template.Versions, err = s.versions(id)
+	return template, err
+}
+
+// plain describes the plain file template id.
+func (s *fileTemplateStore) plain(id string) (dtos.Template, error) {
+	info, err := os.Stat(filepath.Join(s.dir, id+".html"))
+	if err != nil || info.IsDir() {
+		return dtos.Template{}, fmt.Errorf("%w: %s", ErrTemplateNotFound, id)
+	}
+	return dtos.Template{
+		ID:        id,
+		Kind:      dtos.TemplateDocument,
+		Versions:  []int{1},
+		CreatedAt: info.ModTime(),
+		UpdatedAt: info.ModTime(),
+	}, nil
+}
+
+func (s *fileTemplateStore) versions(id string) ([]int, error) {
+	entries, err := os.ReadDir(filepath.Join(s.dir, id))
+	if err != nil {
+		return nil, err
+	}
+	versions := []int{}
+	for _, entry := range entries {
+		if n, err := strconv.Atoi(strings.TrimSuffix(entry.Name(), ".json")); err == nil && n > 0 {
+			versions = append(versions, n)
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// GetVersion reads a version of the template, the latest one when version
+// is 0.
+func (s *fileTemplateStore) GetVersion(id string, version int) (dtos.TemplateVersion, error) {
+	var v dtos.TemplateVersion
+	template, err := s.Get(id)
+	if err != nil {
+		return v, err
+	}
+	if len(template.Versions) == 0 {
+		return v, fmt.Errorf("%w: %s", ErrTemplateNotFound, id)
+	}
+	if version == 0 {
+		version = template.Versions[len(template.Versions)-1]
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, id, templateMeta)); err != nil {
+		if version != 1 {
+			return v, fmt.Errorf("%w: %s@%d", ErrTemplateNotFound, id, version)
+		}
+		b, err := os.ReadFile(filepath.Join(s.dir, id+".html"))
+		return dtos.TemplateVersion{Version: 1, Content: string(b), CreatedAt: template.CreatedAt}, err
+	}
+	b, err := os.ReadFile(filepath.Join(s.dir, id, strconv.Itoa(version)+".json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return v, fmt.Errorf("%w: %s@%d", ErrTemplateNotFound, id, version)
+	}
+	if err != nil {
+		return v, err
+	}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return v, fmt.Errorf("template %s@%d: %w", id, version, err)
+	}
+	return v, nil
+}
+
+func (s *fileTemplateStore) Create(template dtos.Template, version dtos.TemplateVersion) (dtos.Template, error) {
+	if _, err := s.plain(template.ID); err == nil {
+		return dtos.Template{}, fmt.Errorf("%w: %s", ErrTemplateExists, template.ID)
+	}
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return dtos.Template{}, err
+	}
+	dir := filepath.Join(s.dir, template.ID)
+	if err := os.Mkdir(dir, 0o755); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return dtos.Template{}, fmt.Errorf("%w: %s", ErrTemplateExists, template.ID)
+		}
+		return dtos.Template{}, err
+	}
+
+	template.CreatedAt = time.Now().UTC()
+	template.UpdatedAt = template.CreatedAt
+	version.Version, version.CreatedAt = 1, template.CreatedAt
+	err := s.writeVersion(template.ID, version)
+	if err == nil {
+		err = s.writeMeta(template)
+	}
+	if err != nil {
+		os.RemoveAll(dir)
+		return dtos.Template{}, err
+	}
+	return s.Get(template.ID)
+}
+
+func (s *fileTemplateStore) AddVersion(template dtos.Template, version dtos.TemplateVersion) (dtos.Template, error) {
+	current, err := s.Get(template.ID)
+	if err != nil {
+		return current, err
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, template.ID, templateMeta)); err != nil {
+		return current, fmt.Errorf("%w: template %s is a plain file of the templates directory", ErrInvalidRequest, template.ID)
+	}
+
+	version.CreatedAt = time.Now().UTC()
+	version.Version = current.Versions[len(current.Versions)-1] + 1
+	for {
+		err = s.writeVersion(template.ID, version)
+		if !errors.Is(err, fs.ErrExist) {
+			break
+		}
+		version.Version++
+	}
+	if err != nil {
+		return current, err
+	}
+
+	if template.Description != "" {
+		current.Description = template.Description
+	}
+	current.UpdatedAt = version.CreatedAt
+	if err := s.writeMeta(current); err != nil {
+		return current, err
+	}
+	return s.Get(template.ID)
+}
+
+func (s *fileTemplateStore) Delete(id string) error {
+	if _, err := s.Get(id); err != nil {
+		return err
+	}
+	if err := os.RemoveAll(filepath.Join(s.dir, id)); err != nil {
+		return err
+	}
+	if err := os.Remove(filepath.Join(s.dir, id+".html")); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// writeVersion creates the file of the version, failing with fs.ErrExist
+// when the version is already taken. The file is linked once written, for
+// the readers never to see it partly.
+func (s *fileTemplateStore) writeVersion(id string, version dtos.TemplateVersion) error {
+	return s.writeFile(id, strconv.Itoa(version.Version)+".json", version, os.Link)
+}
+
+// writeMeta replaces the description of the template at once.
+func (s *fileTemplateStore) writeMeta(template dtos.Template) error {
+	template.Versions, template.Version = nil, nil
+	return s.writeFile(template.ID, templateMeta, template, os.Rename)
+}
+
+// writeFile writes v as JSON to a temporary file of the template directory,
+// moved to name by place.
+func (s *fileTemplateStore) writeFile(id string, name string, v interface{}, place func(string, string) error) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Join(s.dir, id), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return place(f.Name(), filepath.Join(s.dir, id, name))
+}
@@ -0,0 +1,82 @@
+package services_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenderTemplate(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "contract.html"), []byte(`<h1>{{.Name}}</h1>`), 0o644)
+	hs := services.NewHtml2PdfServiceWithTemplates(dir)
+
+	t.Run("TestRenderTemplateEscapes", func(t *testing.T) {
+		content, err := hs.RenderTemplate(dtos.HtmlRequest{
+			Template: `<p title="{{.Name}}">{{.Name}}</p>`,
+			Data:     map[string]interface{}{"Name": `<b>"Ana"</b>`},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, `<p title="&lt;b&gt;&#34;Ana&#34;&lt;/b&gt;">&lt;b&gt;&#34;Ana&#34;&lt;/b&gt;</p>`, content)
+	})
+
+	t.Run("TestRenderTemplateByName", func(t *testing.T) {
+		content, err := hs.RenderTemplate(dtos.HtmlRequest{TemplateName: "contract", Data: map[string]interface{}{"Name": "Ana"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "<h1>Ana</h1>", content)
+	})
+
+	t.Run("TestRenderTemplateHelpers", func(t *testing.T) {
+		data := map[string]interface{}{
+			"Date":   "2024-03-05T10:00:00Z",
+			"Amount": 1234567.891,
+			"Debt":   -0.004,
+			"Items":  []interface{}{"a", "b"},
+			"Paid":   true,
+		}
+		for template, expected := range map[string]string{
+			`{{formatDate "02/01/2006" .Date}}`:                                 "05/03/2024",
+			`{{formatDate "2006" 0}}`:                                           "1970",
+			`{{number 2 .Amount}}`:                                              "1,234,567.89",
+			`{{formatNumber 2 "," "." .Amount}}`:                                "1.234.567,89",
+			`{{number 2 .Debt}}`:                                                "0.00",
+			`{{number 0 "-1500"}}`:                                              "-1,500",
+			`{{default "n/a" .Missing}}|{{default "n/a" .Paid}}`:                "n/a|true",
+			`{{ternary "paid" "due" .Paid}}`:                                    "paid",
+			`{{if empty .Items}}none{{else}}{{join ", " .Items}}{{end}}`:        "a, b",
+			`{{range $i, $item := .Items}}{{add $i 1}}.{{upper $item}} {{end}}`: "1.A 2.B ",
+			`{{range seq 3}}{{.}}{{end}}`:                                       "123",
+			`{{div 7 2}} {{mul 3 "2"}} {{sub 1 3}}`:                             "3.5 6 -2",
+			`{{replace "a" "o" "banana" | trim}}`:                               "bonono",
+		} {
+			content, err := hs.RenderTemplate(dtos.HtmlRequest{Template: template, Data: data})
+
+			assert.NoError(t, err, template)
+			assert.Equal(t, expected, content, template)
+		}
+	})
+
+	t.Run("TestRenderTemplateInvalid", func(t *testing.T) {
+		for name, request := range map[string]dtos.HtmlRequest{
+			"Syntax":      {Template: "{{.Name"},
+			"UnknownFunc": {Template: "{{shout .Name}}"},
+			"Execution":   {Template: `{{div 1 0}}`},
+			"NotADate":    {Template: `{{formatDate "2006" "tomorrow"}}`},
+			"SeqTooLong":  {Template: `{{range seq 100000}}{{end}}`},
+			"Unknown":     {TemplateName: "missing"},
+			"BadName":     {TemplateName: "../contract"},
+		} {
+			_, err := hs.RenderTemplate(request)
+
+			assert.ErrorIs(t, err, services.ErrInvalidRequest, name)
+		}
+	})
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+// checkURL tells whether the page of the request URL may be rendered: its
+// host must be allowed by the configuration and by the network policy.
+func (r *html2PdfService) checkURL(ctx context.Context, request dtos.HtmlRequest) error {
+	target, err := url.Parse(request.URL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") {
+		return fmt.Errorf("%w: invalid url %q", ErrInvalidRequest, request.URL)
+	}
+	if !matchHost(r.urlHosts, strings.ToLower(target.Hostname())) {
+		return fmt.Errorf("%w: url host %s not allowed", ErrInvalidRequest, target.Hostname())
+	}
+	if reason := r.networkPolicy.check(ctx, request.URL, "", request.Offline); reason != "" {
+		return fmt.Errorf("%w: url blocked: %s", ErrInvalidRequest, reason)
+	}
+	return nil
+}
+
+// urlHeaders are the extra headers sent along the requests to the URL
+// origin, credentials included.
+func urlHeaders(request dtos.HtmlRequest) map[string]string {
+	if request.URL == "" || (len(request.Headers) == 0 && request.BasicAuth == nil) {
+		return nil
+	}
+	headers := make(map[string]string, len(request.Headers)+1)
+	for name, value := range request.Headers {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	if auth := request.BasicAuth; auth != nil {
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.Username + ":" + auth.Password))
+		headers["Authorization"] = "Basic " + credentials
+	}
+	return headers
+}
+
+// urlOrigin is the scheme and host the request URL headers are sent to.
+func urlOrigin(rawURL string) string {
+	target, err := url.Parse(rawURL)
+	if err != nil || rawURL == "" {
+		return ""
+	}
+	return target.Scheme + "://" + target.Host
+}
+
+// loadURL navigates to the request URL. The scripts the inline content
+// gets in its head are added to the page before it loads, the stylesheets
+// once it loaded.
+func (r *html2PdfService) loadURL(request dtos.HtmlRequest) chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		sources := beforeLoadSources(request)
+		if request.WaitFor != nil && request.WaitFor.ReadySignal != nil {
+			sources = append([]string{readySignalSource}, sources...)
+		}
+		for _, source := range sources {
+			if _, err := page.AddScriptToEvaluateOnNewDocument(source).Do(ctx); err != nil {
+				return err
+			}
+		}
+		for _, cookie := range request.Cookies {
+			set := network.SetCookie(cookie.Name, cookie.Value).
+				WithPath(cookie.Path).
+				WithSecure(cookie.Secure).
+				WithHTTPOnly(cookie.HTTPOnly)
+			if cookie.Domain != "" {
+				set = set.WithDomain(cookie.Domain)
+			} else {
+				set = set.WithURL(request.URL)
+			}
+			if err := set.Do(ctx); err != nil {
+				return err
+			}
+		}
+		if err := chromedp.Navigate(request.URL).Do(ctx); err != nil {
+			return err
+		}
+		styles := styleElements(request)
+		if styles == "" {
+			return nil
+		}
+		html, _ := json.Marshal(styles)
+		_, exp, err := runtime.Evaluate(fmt.Sprintf(`document.head.insertAdjacentHTML('beforeend', %s)`, html)).Do(ctx)
+		if err != nil {
+			return err
+		}
+		if exp != nil {
+			return exp
+		}
+		return nil
+	}
+}
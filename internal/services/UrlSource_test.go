@@ -0,0 +1,76 @@
+package services_test
+
+import (
+	"testing"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUrlSource(t *testing.T) {
+	t.Parallel()
+
+	hs := services.NewHtml2PdfServiceWithURLHosts("93.184.216.34", "169.254.169.254", "*.example.com")
+
+	t.Run("TestCheckURLAllowedHost", func(t *testing.T) {
+		err := hs.CheckURL(dtos.HtmlRequest{URL: "https://93.184.216.34/invoice/1"})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("TestCheckURLHostNotAllowed", func(t *testing.T) {
+		err := hs.CheckURL(dtos.HtmlRequest{URL: "https://10.0.0.1/"})
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+		assert.EqualError(t, err, "invalid request: url host 10.0.0.1 not allowed")
+	})
+
+	t.Run("TestCheckURLBlockedByNetworkPolicy", func(t *testing.T) {
+		err := hs.CheckURL(dtos.HtmlRequest{URL: "http://169.254.169.254/latest"})
+
+		assert.EqualError(t, err, "invalid request: url blocked: address 169.254.169.254 denied")
+	})
+
+	t.Run("TestCheckURLScheme", func(t *testing.T) {
+		err := hs.CheckURL(dtos.HtmlRequest{URL: "file:///etc/passwd"})
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestRedirectToHostNotAllowed", func(t *testing.T) {
+		const own = "127.0.0.1:4321"
+		interceptor := services.NewNetworkInterceptor(dtos.HtmlRequest{URL: "https://93.184.216.34/invoice/1"}, own, "93.184.216.34")
+
+		assert.Empty(t, interceptor.Check("http://127.0.0.1:4321/", network.ResourceTypeDocument))
+		assert.Empty(t, interceptor.Check("https://93.184.216.34/invoice/2", network.ResourceTypeDocument))
+		assert.Equal(t, "url host 93.184.216.35 not allowed", interceptor.Check("https://93.184.216.35/", network.ResourceTypeDocument))
+		assert.Empty(t, interceptor.Check("https://93.184.216.35/logo.png", network.ResourceTypeImage))
+	})
+
+	t.Run("TestInlineContentDocumentsNotRestricted", func(t *testing.T) {
+		interceptor := services.NewNetworkInterceptor(dtos.HtmlRequest{}, "127.0.0.1:4321")
+
+		assert.Empty(t, interceptor.Check("https://93.184.216.35/", network.ResourceTypeDocument))
+	})
+
+	t.Run("TestUrlHeaders", func(t *testing.T) {
+		headers := services.UrlHeaders(dtos.HtmlRequest{
+			URL:       "https://app.example.com/",
+			Headers:   map[string]string{"x-tenant": "42"},
+			BasicAuth: &dtos.BasicAuth{Username: "user", Password: "pass"},
+		})
+
+		assert.Equal(t, map[string]string{
+			"X-Tenant":      "42",
+			"Authorization": "Basic dXNlcjpwYXNz",
+		}, headers)
+	})
+
+	t.Run("TestUrlHeadersInlineContent", func(t *testing.T) {
+		headers := services.UrlHeaders(dtos.HtmlRequest{Headers: map[string]string{"x-tenant": "42"}})
+
+		assert.Nil(t, headers)
+	})
+}
@@ -0,0 +1,165 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+)
+
+const (
+	defaultWaitTimeout = 10 * time.Second
+
+	// readySignalSource lets the page tell it is ready to be printed by
+	// calling window.html2pdfReady().
+	readySignalSource = `window.html2pdfReady = function () { window.__html2pdfReady = true; };`
+	readySignalScript = `<script>` + readySignalSource + `</script>`
+)
+
+// WaitError reports a waitFor condition the page did not meet in time.
+type WaitError struct {
+	Condition string
+	Timeout   time.Duration
+	Err       error
+}
+
+func (e *WaitError) Error() string {
+	return fmt.Sprintf("waitFor.%s not met within %s: %v", e.Condition, e.Timeout, e.Err)
+}
+
+func (e *WaitError) Unwrap() error {
+	return e.Err
+}
+
+// waitActions builds the actions that hold the print until every waitFor
+// condition of the request is met. The legacy WaitElementId waits for the
+// element to be present.
+func (r *html2PdfService) waitActions(request dtos.HtmlRequest, tracker *networkTracker) chromedp.Tasks {
+	var tasks chromedp.Tasks
+
+	if request.WaitElementId != "" {
+		tasks = append(tasks, waitCondition("waitElementId", 0, chromedp.WaitReady("#"+request.WaitElementId, chromedp.ByQuery)))
+	}
+
+	wf := request.WaitFor
+	if wf == nil {
+		return tasks
+	}
+
+	if wf.Selector != nil {
+		action := chromedp.WaitReady(wf.Selector.Selector, chromedp.ByQuery)
+		if wf.Selector.Visible {
+			action = chromedp.WaitVisible(wf.Selector.Selector, chromedp.ByQuery)
+		}
+		tasks = append(tasks, waitCondition("selector", wf.Selector.Timeout, action))
+	}
+	if wf.NetworkIdle != nil {
+		idle := time.Duration(wf.NetworkIdle.IdleTime) * time.Millisecond
+		tasks = append(tasks, waitCondition("networkIdle", wf.NetworkIdle.Timeout, chromedp.ActionFunc(func(ctx context.Context) error {
+			return tracker.waitIdle(ctx, idle)
+		})))
+	}
+	if wf.Expression != nil {
+		tasks = append(tasks, waitCondition("expression", wf.Expression.Timeout, chromedp.Poll(wf.Expression.Expression, nil, chromedp.WithPollingTimeout(0))))
+	}
+	if wf.Fonts != nil {
+		tasks = append(tasks, waitCondition("fonts", wf.Fonts.Timeout, chromedp.Evaluate(`document.fonts.ready.then(() => true)`, nil, awaitPromise)))
+	}
+	if wf.ReadySignal != nil {
+		tasks = append(tasks, waitCondition("readySignal", wf.ReadySignal.Timeout, chromedp.Poll(`window.__html2pdfReady === true`, nil, chromedp.WithPollingTimeout(0))))
+	}
+	if wf.Delay > 0 {
+		tasks = append(tasks, chromedp.Sleep(time.Duration(wf.Delay)*time.Millisecond))
+	}
+
+	return tasks
+}
+
+// waitCondition runs action within the condition timeout, given in
+// milliseconds, and reports a WaitError when it is not met.
+func waitCondition(condition string, timeout int, action chromedp.Action) chromedp.ActionFunc {
+	d := defaultWaitTimeout
+	if timeout > 0 {
+		d = time.Duration(timeout) * time.Millisecond
+	}
+
+	return func(ctx context.Context) error {
+		wctx, cancel := context.WithTimeout(ctx, d)
+		defer cancel()
+
+		if err := action.Do(wctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return &WaitError{Condition: condition, Timeout: d, Err: err}
+		}
+		return nil
+	}
+}
+
+func awaitPromise(p *runtime.EvaluateParams) *runtime.EvaluateParams {
+	return p.WithAwaitPromise(true)
+}
+
+// networkTracker follows the requests of a tab to tell for how long the
+// network has been idle.
+type networkTracker struct {
+	lock         *sync.Mutex
+	inflight     map[network.RequestID]bool
+	lastActivity time.Time
+}
+
+func newNetworkTracker() *networkTracker {
+	return &networkTracker{
+		lock:         &sync.Mutex{},
+		inflight:     map[network.RequestID]bool{},
+		lastActivity: time.Now(),
+	}
+}
+
+func (t *networkTracker) listen() chromedp.ActionFunc {
+	return func(ctx context.Context) error {
+		chromedp.ListenTarget(ctx, func(ev interface{}) {
+			t.lock.Lock()
+			defer t.lock.Unlock()
+
+			switch ev := ev.(type) {
+			case *network.EventRequestWillBeSent:
+				t.inflight[ev.RequestID] = true
+			case *network.EventLoadingFinished:
+				delete(t.inflight, ev.RequestID)
+			case *network.EventLoadingFailed:
+				delete(t.inflight, ev.RequestID)
+			default:
+				return
+			}
+			t.lastActivity = time.Now()
+		})
+		return nil
+	}
+}
+
+func (t *networkTracker) waitIdle(ctx context.Context, idle time.Duration) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		t.lock.Lock()
+		done := len(t.inflight) == 0 && time.Since(t.lastActivity) >= idle
+		t.lock.Unlock()
+		if done {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package services_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitFor(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TestWaitErrorMessage", func(t *testing.T) {
+		err := &services.WaitError{Condition: "selector", Timeout: 2 * time.Second, Err: context.DeadlineExceeded}
+
+		assert.Equal(t, "waitFor.selector not met within 2s: context deadline exceeded", err.Error())
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+
+	t.Run("TestInjectHeadExistingHead", func(t *testing.T) {
+		html := services.InjectHead(`<html><HEAD lang="en"><title>t</title></HEAD><body></body></html>`, "<x>")
+
+		assert.Equal(t, `<html><HEAD lang="en"><x><title>t</title></HEAD><body></body></html>`, html)
+	})
+
+	t.Run("TestInjectHeadWithoutHead", func(t *testing.T) {
+		html := services.InjectHead(`<html><body></body></html>`, "<x>")
+
+		assert.Equal(t, `<html><head><x></head><body></body></html>`, html)
+	})
+
+	t.Run("TestInjectHeadFragment", func(t *testing.T) {
+		html := services.InjectHead(`<p>fragment</p>`, "<x>")
+
+		assert.Equal(t, `<x><p>fragment</p>`, html)
+	})
+
+	t.Run("TestDocumentContentReadySignal", func(t *testing.T) {
+		obj := dtos.HtmlRequest{}
+		obj.Content = jsonContent
+		obj.WaitFor = &dtos.WaitFor{ReadySignal: &dtos.WaitSignal{}}
+
+		html := services.DocumentContent(obj)
+
+		assert.Contains(t, html, "window.html2pdfReady")
+	})
+}
@@ -0,0 +1,178 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/logger"
+	"github.com/kolzxx/html2pdf/internal/pdf"
+	"go.uber.org/zap"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the event body, keyed
+	// with the webhook secret, as "sha256=<hex>".
+	SignatureHeader     = "X-Html2pdf-Signature"
+	correlationIdHeader = "X-Correlation-ID"
+)
+
+// webhookNotifier posts the events of the finished jobs to their callback
+// URL, retrying with an exponential backoff.
+type webhookNotifier struct {
+	logger     logger.Logger
+	client     *http.Client
+	policy     *networkPolicy
+	secret     []byte
+	attempts   int
+	backoff    time.Duration
+	inlineSize int
+	publicURL  string
+}
+
+// newWebhookNotifier builds the notifier of the callbacks. Its client does
+// not follow redirects, which the policy did not check, nor go through a
+// proxy, and dials the addresses the policy allowed.
+func newWebhookNotifier(l logger.Logger, c configs.Webhook, network configs.Network) *webhookNotifier {
+	policy := newNetworkPolicy(network, l)
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = policy.dialContext(&net.Dialer{Timeout: c.Timeout})
+	return &webhookNotifier{
+		logger: l,
+		client: &http.Client{
+			Timeout:   c.Timeout,
+			Transport: transport,
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		policy:     policy,
+		secret:     []byte(c.Secret),
+		attempts:   c.MaxAttempts,
+		backoff:    c.Backoff,
+		inlineSize: c.InlineMaxSize,
+		publicURL:  strings.TrimSuffix(c.PublicURL, "/"),
+	}
+}
+
+// check tells whether events may be posted to callbackURL. Callbacks are
+// refused when no secret is configured, their signature could be forged.
+func (n *webhookNotifier) check(ctx context.Context, callbackURL string) error {
+	if len(n.secret) == 0 {
+		return fmt.Errorf("%w: callbacks disabled, no webhook secret configured", ErrInvalidRequest)
+	}
+	if reason := n.policy.check(ctx, callbackURL, "", false); reason != "" {
+		return fmt.Errorf("%w: callback url blocked: %s", ErrInvalidRequest, reason)
+	}
+	return nil
+}
+
+// event builds the event of a finished job, inlining the pdf when it is
+// small enough or no public URL is configured to download it.
+func (n *webhookNotifier) event(job dtos.Job, content []byte, correlationID string) dtos.WebhookEvent {
+	event := dtos.WebhookEvent{
+		ID:            job.ID,
+		Status:        job.Status,
+		Error:         job.Error,
+		Size:          len(content),
+		Pages:         pdfPageCount(content),
+		CorrelationID: correlationID,
+		Timestamp:     time.Now().UTC(),
+	}
+	if len(content) > 0 {
+		if len(content) <= n.inlineSize || n.publicURL == "" {
+			event.Content = content
+		} else {
+			event.DownloadURL = n.publicURL + "/v1/jobs/" + job.ID + "/result"
+		}
+	}
+	return event
+}
+
+// deliver posts event to callbackURL until it is accepted with a 2xx
+// status, the attempts are exhausted or ctx is done.
+func (n *webhookNotifier) deliver(ctx context.Context, callbackURL string, event dtos.WebhookEvent) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		n.logger.Error("Webhook - Encoding event", zap.String("job", event.ID), zap.Error(err))
+		return
+	}
+
+	backoff := n.backoff
+	for attempt := 1; attempt <= n.attempts; attempt++ {
+		status, err := n.post(ctx, callbackURL, body, event.CorrelationID)
+		fields := []interface{}{
+			zap.String("job", event.ID),
+			zap.String("url", callbackURL),
+			zap.Int("attempt", attempt),
+			zap.Int("status", status),
+		}
+		if err == nil {
+			n.logger.Info("Webhook - Delivered", fields...)
+			return
+		}
+		n.logger.Warn("Webhook - Delivery failed", append(fields, zap.Error(err))...)
+
+		if attempt == n.attempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+	n.logger.Error("Webhook - Giving up", zap.String("job", event.ID), zap.String("url", callbackURL))
+}
+
+func (n *webhookNotifier) post(ctx context.Context, callbackURL string, body []byte, correlationID string) (int, error) {
+	if err := n.check(ctx, callbackURL); err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, callbackURL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, "sha256="+sign(n.secret, body))
+	if correlationID != "" {
+		req.Header.Set(correlationIdHeader, correlationID)
+	}
+
+	res, err := n.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	res.Body.Close()
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return res.StatusCode, fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return res.StatusCode, nil
+}
+
+func sign(secret []byte, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// pdfPageCount counts the pages of a pdf, 0 when it cannot be parsed.
+func pdfPageCount(content []byte) int {
+	doc, err := pdf.Parse(content)
+	if err != nil {
+		return 0
+	}
+	return doc.NumPages()
+}
@@ -0,0 +1,144 @@
+package services_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kolzxx/html2pdf/configs"
+	"github.com/kolzxx/html2pdf/internal/dtos"
+	"github.com/kolzxx/html2pdf/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+
+	cfg := configs.Jobs{Workers: 1, QueueSize: 1, Timeout: time.Second, TTL: time.Hour}
+	webhook := configs.Webhook{Secret: "s3cr3t", MaxAttempts: 3, Backoff: time.Millisecond, Timeout: time.Second, InlineMaxSize: 1 << 20}
+	pdf := widthsPdf(100, 200)
+	stub := renderStub{render: func(ctx context.Context, request dtos.HtmlRequest) (dtos.PdfResponse, error) {
+		return dtos.PdfResponse{Content: pdf}, nil
+	}}
+
+	t.Run("TestWebhookDeliveredWithRetries", func(t *testing.T) {
+		var attempts atomic.Int32
+		events := make(chan dtos.WebhookEvent, 1)
+		callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if attempts.Add(1) == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			body, _ := io.ReadAll(r.Body)
+			mac := hmac.New(sha256.New, []byte("s3cr3t"))
+			mac.Write(body)
+			assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), r.Header.Get(services.SignatureHeader))
+			assert.Equal(t, "corr-1", r.Header.Get("X-Correlation-ID"))
+
+			var event dtos.WebhookEvent
+			assert.NoError(t, json.Unmarshal(body, &event))
+			events <- event
+		}))
+		defer callback.Close()
+
+		js := services.NewJobServiceWith(context.Background(), stub, cfg, webhook)
+		job, err := js.Submit(dtos.HtmlRequest{Content: jsonContent, CallbackURL: callback.URL}, "corr-1")
+		assert.NoError(t, err)
+
+		select {
+		case event := <-events:
+			assert.Equal(t, job.ID, event.ID)
+			assert.Equal(t, dtos.JobDone, event.Status)
+			assert.Equal(t, len(pdf), event.Size)
+			assert.Equal(t, 2, event.Pages)
+			assert.Equal(t, pdf, event.Content)
+			assert.Equal(t, "corr-1", event.CorrelationID)
+		case <-time.After(2 * time.Second):
+			t.Fatal("webhook not delivered")
+		}
+		assert.Equal(t, int32(2), attempts.Load())
+	})
+
+	t.Run("TestWebhookDownloadLink", func(t *testing.T) {
+		events := make(chan dtos.WebhookEvent, 1)
+		callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var event dtos.WebhookEvent
+			json.NewDecoder(r.Body).Decode(&event)
+			events <- event
+		}))
+		defer callback.Close()
+
+		webhook := webhook
+		webhook.InlineMaxSize = 10
+		webhook.PublicURL = "https://pdf.example.com/"
+		js := services.NewJobServiceWith(context.Background(), stub, cfg, webhook)
+		job, _ := js.Submit(dtos.HtmlRequest{Content: jsonContent, CallbackURL: callback.URL}, "")
+
+		event := <-events
+		assert.Nil(t, event.Content)
+		assert.Equal(t, "https://pdf.example.com/v1/jobs/"+job.ID+"/result", event.DownloadURL)
+	})
+
+	t.Run("TestWebhookRedirectNotFollowed", func(t *testing.T) {
+		var denied atomic.Int32
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			denied.Add(1)
+		}))
+		defer target.Close()
+		attempts := make(chan struct{}, webhook.MaxAttempts)
+		callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts <- struct{}{}
+			http.Redirect(w, r, strings.Replace(target.URL, "127.0.0.1", "localhost", 1), http.StatusTemporaryRedirect)
+		}))
+		defer callback.Close()
+
+		network := configs.Network{AllowSchemes: []string{"http"}, DenyHosts: []string{"localhost"}}
+		js := services.NewJobServiceWithNetwork(context.Background(), stub, cfg, webhook, network)
+		_, err := js.Submit(dtos.HtmlRequest{Content: jsonContent, CallbackURL: callback.URL}, "")
+		assert.NoError(t, err)
+
+		for i := 0; i < webhook.MaxAttempts; i++ {
+			select {
+			case <-attempts:
+			case <-time.After(2 * time.Second):
+				t.Fatal("webhook not attempted")
+			}
+		}
+		assert.Zero(t, denied.Load())
+	})
+
+	t.Run("TestWebhookCallbackBlocked", func(t *testing.T) {
+		js := services.NewJobServiceWith(context.Background(), stub, cfg, webhook)
+
+		_, err := js.Submit(dtos.HtmlRequest{Content: jsonContent, CallbackURL: "ftp://example.com/hook"}, "")
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+	})
+
+	t.Run("TestWebhookWithoutSecret", func(t *testing.T) {
+		webhook := webhook
+		webhook.Secret = ""
+		js := services.NewJobServiceWith(context.Background(), stub, cfg, webhook)
+
+		_, err := js.Submit(dtos.HtmlRequest{Content: jsonContent, CallbackURL: "http://example.com/hook"}, "")
+
+		assert.ErrorIs(t, err, services.ErrInvalidRequest)
+		assert.ErrorContains(t, err, "no webhook secret configured")
+	})
+
+	t.Run("TestPdfPageCount", func(t *testing.T) {
+		assert.Equal(t, 2, services.PdfPageCount(pdf))
+		assert.Equal(t, 3, services.PdfPageCount(widthsPdf(100, 100, 100)))
+		assert.Equal(t, 0, services.PdfPageCount([]byte("%PDF-1.4 /Type /Page")))
+		assert.Equal(t, 0, services.PdfPageCount(nil))
+	})
+}
@@ -0,0 +1,106 @@
+// Package spreadsheet reads the rows of the CSV files and of the sheets of
+// the XLSX workbooks uploaded for mail merges.
+package spreadsheet
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalid reports a file that is neither a CSV file nor an XLSX
+	// workbook, or one that cannot be read.
+	ErrInvalid = errors.New("invalid spreadsheet")
+	// ErrTooLarge reports a workbook expanding beyond the size allowed.
+	ErrTooLarge = errors.New("spreadsheet too large")
+)
+
+// Row is a non blank row of a file, numbered as the spreadsheet programs
+// show it: by line for a CSV file, by row for a sheet.
+type Row struct {
+	Number int
+	Cells  []string
+}
+
+// Options selects the sheet of a workbook, the first one by default, and
+// the delimiter of a CSV file, guessed from its first line by default.
+type Options struct {
+	Sheet     string
+	Delimiter rune
+	// MaxSize bounds the decompressed size of each part of a workbook.
+	MaxSize int64
+}
+
+var (
+	zipMagic = []byte("PK\x03\x04")
+	oleMagic = []byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1")
+	utf8BOM  = []byte("\xef\xbb\xbf")
+)
+
+// Read returns the non blank rows of data, an XLSX workbook or a CSV file
+// told apart by their content.
+func Read(data []byte, options Options) ([]Row, error) {
+	switch {
+	case bytes.HasPrefix(data, zipMagic):
+		return ReadXLSX(data, options)
+	case bytes.HasPrefix(data, oleMagic):
+		return nil, fmt.Errorf("%w: the XLS format is not supported, save the workbook as XLSX", ErrInvalid)
+	}
+	return ReadCSV(data, options.Delimiter)
+}
+
+// ReadCSV returns the non blank rows of a CSV file. Without a delimiter,
+// the one among comma, semicolon and tab most used on the first line is
+// taken.
+func ReadCSV(data []byte, delimiter rune) ([]Row, error) {
+	data = bytes.TrimPrefix(data, utf8BOM)
+	if !utf8.Valid(data) {
+		return nil, fmt.Errorf("%w: a CSV file must be encoded in UTF-8", ErrInvalid)
+	}
+	if delimiter == 0 {
+		delimiter = guessDelimiter(data)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	rows := []Row{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return rows, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s", ErrInvalid, err)
+		}
+		line, _ := reader.FieldPos(0)
+		rows = appendRow(rows, line, record)
+	}
+}
+
+func guessDelimiter(data []byte) rune {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	delimiter, most := ',', 0
+	for _, candidate := range []rune{',', ';', '\t'} {
+		if n := bytes.Count(line, []byte(string(candidate))); n > most {
+			delimiter, most = candidate, n
+		}
+	}
+	return delimiter
+}
+
+// appendRow adds the cells to rows unless they are all blank.
+func appendRow(rows []Row, number int, cells []string) []Row {
+	for _, cell := range cells {
+		if strings.TrimSpace(cell) != "" {
+			return append(rows, Row{Number: number, Cells: cells})
+		}
+	}
+	return rows
+}
@@ -0,0 +1,143 @@
+package spreadsheet_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/kolzxx/html2pdf/internal/spreadsheet"
+	"github.com/stretchr/testify/assert"
+)
+
+// workbook is an XLSX package of the given parts.
+func workbook(t *testing.T, parts map[string]string) []byte {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		assert.NoError(t, err)
+		w.Write([]byte(content))
+	}
+	assert.NoError(t, archive.Close())
+	return buf.Bytes()
+}
+
+func xlsx(t *testing.T) []byte {
+	return workbook(t, map[string]string{
+		"_rels/.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>
+</Relationships>`,
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Summary" sheetId="1" r:id="rId1"/><sheet name="Staff" sheetId="2" r:id="rId2"/></sheets>
+</workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>
+<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="/xl/worksheets/sheet2.xml"/>
+<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/sharedStrings" Target="sharedStrings.xml"/>
+<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>
+</Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<si><t>name</t></si><si><t>hired</t></si><si><r><t>Ann </t></r><r><t>Lee</t></r></si><si><t>salary</t></si>
+</sst>`,
+		"xl/styles.xml": `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">
+<numFmts><numFmt numFmtId="164" formatCode="dd&quot;/&quot;mm&quot;/&quot;yyyy"/><numFmt numFmtId="165" formatCode="#,##0.00 &quot;EUR&quot;"/></numFmts>
+<cellXfs><xf numFmtId="0"/><xf numFmtId="164"/><xf numFmtId="165"/><xf numFmtId="20"/></cellXfs>
+</styleSheet>`,
+		"xl/worksheets/sheet1.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="inlineStr"><is><t>summary</t></is></c></row>
+</sheetData></worksheet>`,
+		"xl/worksheets/sheet2.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>3</v></c><c r="D1" t="inlineStr"><is><t>start</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>2</v></c><c r="B2" s="1"><v>45292</v></c><c r="C2" s="2"><v>2500.5</v></c><c r="D2" s="3"><v>0.375</v></c></row>
+<row r="3"><c r="A3"/></row>
+<row r="5"><c r="A5" t="inlineStr"><is><t>Bob</t></is></c><c r="E5" t="b"><v>1</v></c></row>
+</sheetData></worksheet>`,
+	})
+}
+
+func TestReadCSV(t *testing.T) {
+	t.Parallel()
+
+	for name, test := range map[string]struct {
+		data      string
+		delimiter rune
+		expected  []spreadsheet.Row
+	}{
+		"Comma": {
+			data:     "name,city\nAnn,\"Paris, FR\"\n",
+			expected: []spreadsheet.Row{{Number: 1, Cells: []string{"name", "city"}}, {Number: 2, Cells: []string{"Ann", "Paris, FR"}}},
+		},
+		"SemicolonWithBOM": {
+			data:     "\xef\xbb\xbfname;amount\r\n\r\n;\r\nBob;1,5\r\n",
+			expected: []spreadsheet.Row{{Number: 1, Cells: []string{"name", "amount"}}, {Number: 4, Cells: []string{"Bob", "1,5"}}},
+		},
+		"Tab": {
+			data:     "name\tnote\nCid\ta 12\" screen\n",
+			expected: []spreadsheet.Row{{Number: 1, Cells: []string{"name", "note"}}, {Number: 2, Cells: []string{"Cid", "a 12\" screen"}}},
+		},
+		"GivenDelimiter": {
+			data:      "name|city\nAnn,Lee|Rome\n",
+			delimiter: '|',
+			expected:  []spreadsheet.Row{{Number: 1, Cells: []string{"name", "city"}}, {Number: 2, Cells: []string{"Ann,Lee", "Rome"}}},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rows, err := spreadsheet.ReadCSV([]byte(test.data), test.delimiter)
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, rows)
+		})
+	}
+
+	t.Run("NotUTF8", func(t *testing.T) {
+		_, err := spreadsheet.ReadCSV([]byte("name\nJos\xe9\n"), 0)
+
+		assert.ErrorIs(t, err, spreadsheet.ErrInvalid)
+	})
+}
+
+func TestReadXLSX(t *testing.T) {
+	t.Parallel()
+
+	t.Run("FirstSheet", func(t *testing.T) {
+		rows, err := spreadsheet.Read(xlsx(t), spreadsheet.Options{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []spreadsheet.Row{{Number: 1, Cells: []string{"summary"}}}, rows)
+	})
+
+	t.Run("SheetByName", func(t *testing.T) {
+		rows, err := spreadsheet.Read(xlsx(t), spreadsheet.Options{Sheet: "staff"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, []spreadsheet.Row{
+			{Number: 1, Cells: []string{"name", "hired", "salary", "start"}},
+			{Number: 2, Cells: []string{"Ann Lee", "2024-01-01", "2500.5", "09:00:00"}},
+			{Number: 5, Cells: []string{"Bob", "", "", "", "TRUE"}},
+		}, rows)
+	})
+
+	t.Run("UnknownSheet", func(t *testing.T) {
+		_, err := spreadsheet.Read(xlsx(t), spreadsheet.Options{Sheet: "Payroll"})
+
+		assert.ErrorIs(t, err, spreadsheet.ErrInvalid)
+	})
+
+	t.Run("TooLarge", func(t *testing.T) {
+		_, err := spreadsheet.Read(xlsx(t), spreadsheet.Options{Sheet: "Staff", MaxSize: 100})
+
+		assert.ErrorIs(t, err, spreadsheet.ErrTooLarge)
+	})
+
+	t.Run("NotAWorkbook", func(t *testing.T) {
+		_, err := spreadsheet.Read(workbook(t, map[string]string{"readme.txt": "hello"}), spreadsheet.Options{})
+
+		assert.ErrorIs(t, err, spreadsheet.ErrInvalid)
+	})
+
+	t.Run("XLS", func(t *testing.T) {
+		_, err := spreadsheet.Read([]byte("\xd0\xcf\x11\xe0\xa1\xb1\x1a\xe1rest"), spreadsheet.Options{})
+
+		assert.ErrorIs(t, err, spreadsheet.ErrInvalid)
+	})
+}
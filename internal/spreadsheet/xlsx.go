@@ -0,0 +1,344 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"math"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxSize bounds the decompressed size of the parts of a workbook
+// when the options do not.
+const defaultMaxSize = 64 << 20
+
+// maxColumns is the number of columns of a sheet, up to XFD.
+const maxColumns = 16384
+
+// workbook reads the parts of an XLSX package.
+type workbook struct {
+	files   map[string]*zip.File
+	maxSize int64
+}
+
+type relationships struct {
+	Items []struct {
+		ID     string `xml:"Id,attr"`
+		Type   string `xml:"Type,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type workbookPart struct {
+	Properties struct {
+		Date1904 bool `xml:"date1904,attr"`
+	} `xml:"workbookPr"`
+	Sheets []struct {
+		Name  string     `xml:"name,attr"`
+		Attrs []xml.Attr `xml:",any,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+// stringItem is the rich or plain text of a shared string or an inline
+// string, its phonetic runs left out.
+type stringItem struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s stringItem) text() string {
+	text := s.T
+	for _, run := range s.Runs {
+		text += run.T
+	}
+	return text
+}
+
+type stylesPart struct {
+	NumFmts []struct {
+		ID   int    `xml:"numFmtId,attr"`
+		Code string `xml:"formatCode,attr"`
+	} `xml:"numFmts>numFmt"`
+	CellXfs []struct {
+		NumFmtID int `xml:"numFmtId,attr"`
+	} `xml:"cellXfs>xf"`
+}
+
+type sheetPart struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			R      string     `xml:"r,attr"`
+			T      string     `xml:"t,attr"`
+			S      int        `xml:"s,attr"`
+			V      string     `xml:"v"`
+			Inline stringItem `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX returns the non blank rows of a sheet of an XLSX workbook. The
+// cells are read as shown, save for the numbers, written without their
+// format, and the dates, written as 2006-01-02, 15:04:05 or both.
+func ReadXLSX(data []byte, options Options) ([]Row, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalid, err)
+	}
+	wb := &workbook{files: map[string]*zip.File{}, maxSize: options.MaxSize}
+	if wb.maxSize <= 0 {
+		wb.maxSize = defaultMaxSize
+	}
+	for _, file := range archive.File {
+		wb.files[strings.TrimPrefix(path.Clean("/"+file.Name), "/")] = file
+	}
+
+	root := wb.target("", "_rels/.rels", "/officeDocument", "xl/workbook.xml")
+	var book workbookPart
+	if err := wb.decode(root, &book); err != nil {
+		return nil, err
+	}
+	dir := path.Dir(root)
+	rels := path.Join(dir, "_rels", path.Base(root)+".rels")
+
+	sheet, err := wb.sheet(book, options.Sheet, dir, rels)
+	if err != nil {
+		return nil, err
+	}
+	var shared struct {
+		Items []stringItem `xml:"si"`
+	}
+	if err := wb.decode(wb.target(dir, rels, "/sharedStrings", path.Join(dir, "sharedStrings.xml")), &shared); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var styles stylesPart
+	if err := wb.decode(wb.target(dir, rels, "/styles", path.Join(dir, "styles.xml")), &styles); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	var part sheetPart
+	if err := wb.decode(sheet, &part); err != nil {
+		return nil, err
+	}
+
+	formats := dateFormats(styles)
+	rows := []Row{}
+	for i, row := range part.Rows {
+		number := row.R
+		if number == 0 {
+			number = i + 1
+		}
+		cells := []string{}
+		for _, cell := range row.Cells {
+			column := len(cells)
+			if cell.R != "" {
+				if column, err = columnIndex(cell.R); err != nil {
+					return nil, err
+				}
+			}
+			for len(cells) <= column {
+				cells = append(cells, "")
+			}
+			value := cell.V
+			switch cell.T {
+			case "s":
+				n, err := strconv.Atoi(strings.TrimSpace(cell.V))
+				if err != nil || n < 0 || n >= len(shared.Items) {
+					return nil, fmt.Errorf("%w: cell %s refers to an unknown shared string", ErrInvalid, cell.R)
+				}
+				value = shared.Items[n].text()
+			case "inlineStr":
+				value = cell.Inline.text()
+			case "b":
+				value = map[string]string{"1": "TRUE", "0": "FALSE"}[strings.TrimSpace(cell.V)]
+			case "", "n":
+				value = numberText(cell.V, formats[cell.S], book.Properties.Date1904)
+			}
+			cells[column] = value
+		}
+		rows = appendRow(rows, number, cells)
+	}
+	return rows, nil
+}
+
+// sheet returns the part of the sheet named name, or of the first sheet.
+func (wb *workbook) sheet(book workbookPart, name string, dir string, rels string) (string, error) {
+	if len(book.Sheets) == 0 {
+		return "", fmt.Errorf("%w: the workbook has no sheet", ErrInvalid)
+	}
+	index := 0
+	if name != "" {
+		index = -1
+		for i, sheet := range book.Sheets {
+			if strings.EqualFold(sheet.Name, name) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return "", fmt.Errorf("%w: the workbook has no sheet %q", ErrInvalid, name)
+		}
+	}
+
+	var id string
+	for _, attr := range book.Sheets[index].Attrs {
+		if attr.Name.Local == "id" && strings.Contains(attr.Name.Space, "relationships") {
+			id = attr.Value
+		}
+	}
+	var r relationships
+	if err := wb.decode(rels, &r); err != nil {
+		return "", err
+	}
+	for _, item := range r.Items {
+		if item.ID == id {
+			return resolveTarget(dir, item.Target), nil
+		}
+	}
+	return "", fmt.Errorf("%w: sheet %q has no part", ErrInvalid, book.Sheets[index].Name)
+}
+
+// target returns the part a relationship of the given type of rels points
+// to, or fallback.
+func (wb *workbook) target(dir string, rels string, relType string, fallback string) string {
+	var r relationships
+	if err := wb.decode(rels, &r); err == nil {
+		for _, item := range r.Items {
+			if strings.HasSuffix(item.Type, relType) {
+				return resolveTarget(dir, item.Target)
+			}
+		}
+	}
+	return fallback
+}
+
+func resolveTarget(dir string, target string) string {
+	if strings.HasPrefix(target, "/") {
+		return strings.TrimPrefix(path.Clean(target), "/")
+	}
+	return strings.TrimPrefix(path.Clean("/"+path.Join(dir, target)), "/")
+}
+
+// decode unmarshals a part of the package, failing with fs.ErrNotExist
+// when there is no such part.
+func (wb *workbook) decode(name string, v interface{}) error {
+	file, ok := wb.files[name]
+	if !ok {
+		return fmt.Errorf("%w: %s: %w", ErrInvalid, name, fs.ErrNotExist)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalid, name, err)
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(io.LimitReader(rc, wb.maxSize+1))
+	if err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalid, name, err)
+	}
+	if int64(len(data)) > wb.maxSize {
+		return fmt.Errorf("%w: %s exceeds %d bytes", ErrTooLarge, name, wb.maxSize)
+	}
+	if err := xml.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("%w: %s: %s", ErrInvalid, name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the column, from 0, of a cell reference such as B12.
+func columnIndex(ref string) (int, error) {
+	column := 0
+	for i := 0; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		column = column*26 + int(ref[i]-'A') + 1
+		if column > maxColumns {
+			break
+		}
+	}
+	if column == 0 || column > maxColumns {
+		return 0, fmt.Errorf("%w: invalid cell reference %q", ErrInvalid, ref)
+	}
+	return column - 1, nil
+}
+
+// dateFormat tells how a number formatted as a date is written.
+type dateFormat struct {
+	date bool
+	time bool
+}
+
+var (
+	// literalExpr matches the parts of a number format that are shown as
+	// is: quoted text, escaped characters and bracketed colors or
+	// conditions.
+	literalExpr = regexp.MustCompile(`"[^"]*"|\\.|\[[^\]]*\]`)
+	elapsedExpr = regexp.MustCompile(`\[[hHmMsS]+\]`)
+	dateExpr    = regexp.MustCompile(`[yYdD]`)
+	timeExpr    = regexp.MustCompile(`[hHsS]`)
+)
+
+// dateFormats returns the date formats of the cell styles, indexed by
+// style.
+func dateFormats(styles stylesPart) map[int]dateFormat {
+	codes := map[int]string{}
+	for _, f := range styles.NumFmts {
+		codes[f.ID] = f.Code
+	}
+	formats := map[int]dateFormat{}
+	for i, xf := range styles.CellXfs {
+		id := xf.NumFmtID
+		switch {
+		case id >= 14 && id <= 17, id >= 27 && id <= 31, id >= 34 && id <= 36, id >= 50 && id <= 58:
+			formats[i] = dateFormat{date: true}
+		case id >= 18 && id <= 21, id >= 32 && id <= 33, id >= 45 && id <= 47:
+			formats[i] = dateFormat{time: true}
+		case id == 22:
+			formats[i] = dateFormat{date: true, time: true}
+		case codes[id] != "":
+			code := literalExpr.ReplaceAllString(codes[id], "")
+			f := dateFormat{date: dateExpr.MatchString(code), time: timeExpr.MatchString(code) || elapsedExpr.MatchString(codes[id])}
+			// m alone is a month, or minutes next to hours or seconds
+			if !f.date && !f.time && strings.ContainsAny(code, "mM") {
+				f.date = true
+			}
+			if f.date || f.time {
+				formats[i] = f
+			}
+		}
+	}
+	return formats
+}
+
+// numberText writes the value of a numeric cell, as a date when its format
+// is one.
+func numberText(value string, format dateFormat, date1904 bool) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return value
+	}
+	if !format.date && !format.time {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	epoch := time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	if date1904 {
+		epoch = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	}
+	days := math.Floor(f)
+	seconds := math.Round((f - days) * 86400)
+	t := epoch.AddDate(0, 0, int(days)).Add(time.Duration(seconds) * time.Second)
+	switch {
+	case format.date && format.time:
+		return t.Format("2006-01-02 15:04:05")
+	case format.time:
+		return t.Format("15:04:05")
+	}
+	return t.Format("2006-01-02")
+}